@@ -0,0 +1,40 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// pinCPUs sets the CPU affinity of every thread in the process with
+// sched_setaffinity. Threads started later inherit the affinity.
+func pinCPUs(cpus []int) error {
+	var mask [16]uint64 // 1024 CPUs
+	for _, cpu := range cpus {
+		if len(mask)*64 <= cpu {
+			return fmt.Errorf("CPU %d is out of range", cpu)
+		}
+		mask[cpu/64] |= 1 << uint(cpu%64)
+	}
+	tids := []int{0} // 0 is the calling thread
+	if tasks, err := ioutil.ReadDir("/proc/self/task"); err == nil {
+		tids = tids[:0]
+		for _, fi := range tasks {
+			if tid, err := strconv.Atoi(fi.Name()); err == nil {
+				tids = append(tids, tid)
+			}
+		}
+	}
+	for _, tid := range tids {
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY,
+			uintptr(tid), uintptr(len(mask)*8), uintptr(unsafe.Pointer(&mask[0])))
+		if errno != 0 {
+			return fmt.Errorf("sched_setaffinity failed for thread %d. %s", tid, errno)
+		}
+	}
+	return nil
+}
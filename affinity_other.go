@@ -0,0 +1,12 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+//go:build !linux
+// +build !linux
+
+package main
+
+import "errors"
+
+func pinCPUs(cpus []int) error {
+	return errors.New("pinning to CPUs is only supported on linux")
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// benchSuite runs the calls for the suite with the id as sub-benchmarks named
+// after each package so go test -bench and benchstat can be used. The same
+// call functions are used as when run from main.
+func benchSuite(b *testing.B, id string) {
+	var s *suite
+	for _, ts := range suites {
+		if ts.id() == id {
+			s = ts
+			break
+		}
+	}
+	if s == nil {
+		b.Fatalf("suite %s not found", id)
+	}
+	if s.fixedGC {
+		defer fixGC()()
+	}
+	for _, p := range packages {
+		c := p.calls[s.fun]
+		if c == nil {
+			continue
+		}
+		b.Run(p.name, func(b *testing.B) {
+			if s.isolate {
+				benchIsolated(b, s, p)
+				return
+			}
+			benchErr = nil
+			c.fun(b)
+			if benchErr != nil {
+				b.Error(benchErr)
+			}
+		})
+	}
+}
+
+// benchChildEnv is set in the environment of the go test child process that
+// runs a call from an isolated suite. The value is the suite/package id of
+// the call.
+const benchChildEnv = "GO_JSON_BENCH_CHILD"
+
+// benchIsolated runs the call for a suite that is isolated in a child go
+// test process with the same number of iterations. The child runs the call
+// with runChild as main does and the time, bytes, and allocations per op it
+// measured are reported.
+func benchIsolated(b *testing.B, s *suite, p *pkg) {
+	id := s.fun + "/" + p.name
+	cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.benchtime=%dx", b.N))
+	cmd.Env = append(os.Environ(), benchChildEnv+"="+id)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		b.Fatalf("%s failed in a child process. %s %s", id, err, lastLine(string(out)))
+	}
+	var cr childResult
+	if err = json.Unmarshal([]byte(lastLine(string(out))), &cr); err != nil {
+		b.Fatalf("%s bad child result. %s", id, err)
+	}
+	if 0 < len(cr.Err) {
+		b.Fatalf("%s failed in a child process. %s", id, cr.Err)
+	}
+	b.ReportMetric(float64(cr.Ns), "ns/op")
+	b.ReportMetric(float64(cr.Bytes), "B/op")
+	b.ReportMetric(float64(cr.Allocs), "allocs/op")
+}
+
+// writeBenchConfig writes the module version of each package as a
+// configuration line in the go test benchmark output so the versions are
+// kept with the results by tools such as benchstat.
+func writeBenchConfig() {
+	if f := flag.Lookup("test.bench"); f == nil || len(f.Value.String()) == 0 {
+		return
+	}
+	for _, p := range packages {
+		p.version = moduleVersion(p.module)
+		fmt.Printf("%s: %s\n", p.module, p.version)
+	}
+}
+
+// benchName returns the go benchmark function name for a suite id such as
+// BenchmarkUnmarshalStruct for unmarshal-struct.
+func benchName(id string) string {
+	var b strings.Builder
+	b.WriteString("Benchmark")
+	for _, part := range strings.Split(id, "-") {
+		if 0 < len(part) {
+			b.WriteString(strings.ToUpper(part[:1]))
+			b.WriteString(part[1:])
+		}
+	}
+	return b.String()
+}
+
+// writeBenchTests writes a go test file with a benchmark function for each
+// suite.
+func writeBenchTests(path string) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by go run . -gen-bench; DO NOT EDIT.\n\n")
+	buf.WriteString("package main\n\nimport (\n\t\"flag\"\n\t\"os\"\n\t\"testing\"\n)\n")
+	buf.WriteString("\nfunc TestMain(m *testing.M) {\n\tflag.Parse()\n")
+	buf.WriteString("\tif id := os.Getenv(benchChildEnv); 0 < len(id) {\n\t\trunChild(packages, id)\n\t\treturn\n\t}\n")
+	buf.WriteString("\twriteBenchConfig()\n\tos.Exit(m.Run())\n}\n")
+	for _, s := range suites {
+		fmt.Fprintf(&buf, "\n// %s runs the %s suite.\n", benchName(s.id()), s.id())
+		fmt.Fprintf(&buf, "func %s(b *testing.B) {\n\tbenchSuite(b, %q)\n}\n", benchName(s.id()), s.id())
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("Failed to format %s. %s\n", path, err)
+	}
+	if err = ioutil.WriteFile(path, src, 0644); err != nil {
+		log.Fatalf("Failed to write %s. %s\n", path, err)
+	}
+}
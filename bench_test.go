@@ -0,0 +1,159 @@
+// Code generated by go run . -gen-bench; DO NOT EDIT.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if id := os.Getenv(benchChildEnv); 0 < len(id) {
+		runChild(packages, id)
+		return
+	}
+	writeBenchConfig()
+	os.Exit(m.Run())
+}
+
+// BenchmarkParse runs the parse suite.
+func BenchmarkParse(b *testing.B) {
+	benchSuite(b, "parse")
+}
+
+// BenchmarkNumbers runs the numbers suite.
+func BenchmarkNumbers(b *testing.B) {
+	benchSuite(b, "numbers")
+}
+
+// BenchmarkNumbersExact runs the numbers-exact suite.
+func BenchmarkNumbersExact(b *testing.B) {
+	benchSuite(b, "numbers-exact")
+}
+
+// BenchmarkParseError runs the parse-error suite.
+func BenchmarkParseError(b *testing.B) {
+	benchSuite(b, "parse-error")
+}
+
+// BenchmarkSenParse runs the sen-parse suite.
+func BenchmarkSenParse(b *testing.B) {
+	benchSuite(b, "sen-parse")
+}
+
+// BenchmarkValidate runs the validate suite.
+func BenchmarkValidate(b *testing.B) {
+	benchSuite(b, "validate")
+}
+
+// BenchmarkDecode runs the decode suite.
+func BenchmarkDecode(b *testing.B) {
+	benchSuite(b, "decode")
+}
+
+// BenchmarkCallback runs the callback suite.
+func BenchmarkCallback(b *testing.B) {
+	benchSuite(b, "callback")
+}
+
+// BenchmarkUnmarshalStruct runs the unmarshal-struct suite.
+func BenchmarkUnmarshalStruct(b *testing.B) {
+	benchSuite(b, "unmarshal-struct")
+}
+
+// BenchmarkUnmarshalStrict runs the unmarshal-strict suite.
+func BenchmarkUnmarshalStrict(b *testing.B) {
+	benchSuite(b, "unmarshal-strict")
+}
+
+// BenchmarkUnmarshalUnknown runs the unmarshal-unknown suite.
+func BenchmarkUnmarshalUnknown(b *testing.B) {
+	benchSuite(b, "unmarshal-unknown")
+}
+
+// BenchmarkMarshal runs the marshal suite.
+func BenchmarkMarshal(b *testing.B) {
+	benchSuite(b, "marshal")
+}
+
+// BenchmarkMarshalStruct runs the marshal-struct suite.
+func BenchmarkMarshalStruct(b *testing.B) {
+	benchSuite(b, "marshal-struct")
+}
+
+// BenchmarkDecompose runs the decompose suite.
+func BenchmarkDecompose(b *testing.B) {
+	benchSuite(b, "decompose")
+}
+
+// BenchmarkRecompose runs the recompose suite.
+func BenchmarkRecompose(b *testing.B) {
+	benchSuite(b, "recompose")
+}
+
+// BenchmarkBuild runs the build suite.
+func BenchmarkBuild(b *testing.B) {
+	benchSuite(b, "build")
+}
+
+// BenchmarkModify runs the modify suite.
+func BenchmarkModify(b *testing.B) {
+	benchSuite(b, "modify")
+}
+
+// BenchmarkSenWrite runs the sen-write suite.
+func BenchmarkSenWrite(b *testing.B) {
+	benchSuite(b, "sen-write")
+}
+
+// BenchmarkMarshalFloats runs the marshal-floats suite.
+func BenchmarkMarshalFloats(b *testing.B) {
+	benchSuite(b, "marshal-floats")
+}
+
+// BenchmarkMarshalHtml runs the marshal-html suite.
+func BenchmarkMarshalHtml(b *testing.B) {
+	benchSuite(b, "marshal-html")
+}
+
+// BenchmarkMarshalNoHtml runs the marshal-no-html suite.
+func BenchmarkMarshalNoHtml(b *testing.B) {
+	benchSuite(b, "marshal-no-html")
+}
+
+// BenchmarkParseFixedGc runs the parse-fixed-gc suite.
+func BenchmarkParseFixedGc(b *testing.B) {
+	benchSuite(b, "parse-fixed-gc")
+}
+
+// BenchmarkUnmarshalStructFixedGc runs the unmarshal-struct-fixed-gc suite.
+func BenchmarkUnmarshalStructFixedGc(b *testing.B) {
+	benchSuite(b, "unmarshal-struct-fixed-gc")
+}
+
+// BenchmarkExtractFields runs the extract-fields suite.
+func BenchmarkExtractFields(b *testing.B) {
+	benchSuite(b, "extract-fields")
+}
+
+// BenchmarkFile1 runs the file1 suite.
+func BenchmarkFile1(b *testing.B) {
+	benchSuite(b, "file1")
+}
+
+// BenchmarkSmallFile runs the small-file suite.
+func BenchmarkSmallFile(b *testing.B) {
+	benchSuite(b, "small-file")
+}
+
+// BenchmarkLargeFile runs the large-file suite.
+func BenchmarkLargeFile(b *testing.B) {
+	benchSuite(b, "large-file")
+}
+
+// BenchmarkExtractLog runs the extract-log suite.
+func BenchmarkExtractLog(b *testing.B) {
+	benchSuite(b, "extract-log")
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+	"time"
+)
+
+var (
+	benchTime string        // duration or Nx for a fixed number of iterations
+	budget    time.Duration // wall-clock budget for each suite
+)
+
+// callBenchtime returns the benchtime to use for each call in the suite. The
+// suite benchtime takes precedence over the global benchtime. If there is a
+// budget, durations are reduced so that the suite fits in the budget. Fixed
+// iteration counts are not changed.
+func (s *suite) callBenchtime(calls int) string {
+	bt := benchTime
+	if 0 < len(s.benchtime) {
+		bt = s.benchtime
+	}
+	if budget <= 0 || calls <= 0 || strings.HasSuffix(bt, "x") {
+		return bt
+	}
+	d := time.Second // the testing package default
+	if 0 < len(bt) {
+		var err error
+		if d, err = time.ParseDuration(bt); err != nil {
+			log.Fatalf("Invalid benchtime %s. %s\n", bt, err)
+		}
+	}
+	// The testing package runs the benchmark a few times with fewer
+	// iterations to decide on the number of iterations for the final run so
+	// a call takes up to about twice the benchtime.
+	if share := budget / time.Duration(calls*2); share < d {
+		d = share
+	}
+	return d.String()
+}
+
+// useBenchtime sets the benchtime used by testing.Benchmark and returns a
+// function that restores the previous value. An empty benchtime leaves the
+// current value unchanged.
+func useBenchtime(bt string) func() {
+	f := flag.Lookup("test.benchtime")
+	orig := f.Value.String()
+	if len(bt) == 0 {
+		return func() {}
+	}
+	if err := f.Value.Set(bt); err != nil {
+		log.Fatalf("Invalid benchtime %s. %s\n", bt, err)
+	}
+	return func() { _ = f.Value.Set(orig) }
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCallBenchtime(t *testing.T) {
+	defer func(bt string, b time.Duration) { benchTime, budget = bt, b }(benchTime, budget)
+
+	for _, tc := range []struct {
+		global string
+		suite  string
+		budget time.Duration
+		calls  int
+		expect string
+	}{
+		{expect: ""},
+		{global: "2s", expect: "2s"},
+		{global: "2s", suite: "10x", expect: "10x"},
+		{global: "100x", budget: time.Second, calls: 5, expect: "100x"},
+		{budget: 10 * time.Second, calls: 5, expect: "1s"},
+		{budget: 10 * time.Second, calls: 10, expect: "500ms"},
+		{global: "300ms", budget: 10 * time.Second, calls: 10, expect: "300ms"},
+		{suite: "5s", budget: 4 * time.Second, calls: 2, expect: "1s"},
+	} {
+		benchTime = tc.global
+		budget = tc.budget
+		s := suite{benchtime: tc.suite}
+		if got := s.callBenchtime(tc.calls); got != tc.expect {
+			t.Errorf("%+v expected %q, got %q", tc, tc.expect, got)
+		}
+	}
+}
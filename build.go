@@ -0,0 +1,57 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"testing"
+)
+
+// buildFile is the document each package builds in the build suite. The
+// builders add each member with an explicit call as an application would so
+// the document is always the patient sample even when a different sample
+// file is given. Members are added in sorted key order since that is how
+// encoding/json writes maps.
+const buildFile = "data/patient.json"
+
+// buildDiv is the text div member of the build document.
+const buildDiv = "<div xmlns=\"http://www.w3.org/1999/xhtml\">\n\t\t\t<table>\n\t\t\t\t<tbody>\n\t\t\t\t\t<tr>\n\t\t\t\t\t\t<td>Name</td>\n\t\t\t\t\t\t<td>Peter James \n              <b>Chalmers</b> (&quot;Jim&quot;)\n            </td>\n\t\t\t\t\t</tr>\n\t\t\t\t\t<tr>\n\t\t\t\t\t\t<td>Address</td>\n\t\t\t\t\t\t<td>534 Erewhon, Pleasantville, Vic, 3999</td>\n\t\t\t\t\t</tr>\n\t\t\t\t\t<tr>\n\t\t\t\t\t\t<td>Contacts</td>\n\t\t\t\t\t\t<td>Home: unknown. Work: (03) 5555 6473</td>\n\t\t\t\t\t</tr>\n\t\t\t\t\t<tr>\n\t\t\t\t\t\t<td>Id</td>\n\t\t\t\t\t\t<td>MRN: 12345 (Acme Healthcare)</td>\n\t\t\t\t\t</tr>\n\t\t\t\t</tbody>\n\t\t\t</table>\n\t\t</div>"
+
+// buildExpect returns the output each package must build. It is the build
+// file written by encoding/json without HTML escaping since fastjson does not
+// escape HTML characters.
+func buildExpect() []byte {
+	data, err := ioutil.ReadFile(buildFile)
+	if err != nil {
+		log.Fatalf("Failed to read %s. %s\n", buildFile, err)
+	}
+	var v interface{}
+	if err = json.Unmarshal(data, &v); err != nil {
+		log.Fatalf("Failed to parse %s. %s\n", buildFile, err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err = enc.Encode(v); err != nil {
+		log.Fatalf("Failed to encode %s. %s\n", buildFile, err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})
+}
+
+// benchBuild verifies the build function output matches the expected output
+// and then benchmarks building the document.
+func benchBuild(b *testing.B, build func() []byte) {
+	if out := build(); !bytes.Equal(out, buildExpect()) {
+		benchErr = fmt.Errorf("built %s differs from the expected output", out)
+		b.Fail()
+		return
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = build()
+	}
+}
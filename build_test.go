@@ -0,0 +1,19 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import "testing"
+
+func TestBuildOutput(t *testing.T) {
+	setBenchtime(t, "1x")
+	for _, p := range packages {
+		c := p.calls["build"]
+		if c == nil {
+			continue
+		}
+		benchErr = nil
+		if res := testing.Benchmark(c.fun); res.N == 0 || benchErr != nil {
+			t.Errorf("%s.%s failed. %v", p.name, c.name, benchErr)
+		}
+	}
+}
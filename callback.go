@@ -0,0 +1,142 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+)
+
+// callbackPath is the top level key that strings are collected under by the
+// callback suite.
+const callbackPath = "name"
+
+// collector is the handler used by the callback suite. Each package feeds
+// tokens to a collector so that all packages compute the same results
+// which are the number of object keys at each depth, the sum of all numbers,
+// and all the strings under the callbackPath.
+type collector struct {
+	keys    []int
+	sum     float64
+	strs    []string
+	depth   int
+	current string // current top level key
+}
+
+func (c *collector) reset() {
+	c.keys = c.keys[:0]
+	c.sum = 0.0
+	c.strs = c.strs[:0]
+	c.depth = 0
+	c.current = ""
+}
+
+func (c *collector) start() {
+	c.depth++
+}
+
+func (c *collector) end() {
+	c.depth--
+}
+
+func (c *collector) key(k string) {
+	for len(c.keys) < c.depth {
+		c.keys = append(c.keys, 0)
+	}
+	c.keys[c.depth-1]++
+	if c.depth == 1 {
+		c.current = k
+	}
+}
+
+func (c *collector) num(f float64) {
+	c.sum += f
+}
+
+func (c *collector) str(s string) {
+	if c.current == callbackPath {
+		c.strs = append(c.strs, s)
+	}
+}
+
+// check returns an error if the collected results do not match the expected
+// results.
+func (c *collector) check(expect *collector) error {
+	if c.sum != expect.sum {
+		return fmt.Errorf("sum of %f, expected %f", c.sum, expect.sum)
+	}
+	if len(c.keys) != len(expect.keys) || len(c.strs) != len(expect.strs) {
+		return fmt.Errorf("collected %v keys and %d strings, expected %v keys and %d strings",
+			c.keys, len(c.strs), expect.keys, len(expect.strs))
+	}
+	for i, k := range expect.keys {
+		if c.keys[i] != k {
+			return fmt.Errorf("%v keys per depth, expected %v", c.keys, expect.keys)
+		}
+	}
+	for i, s := range expect.strs {
+		if c.strs[i] != s {
+			return fmt.Errorf("collected string %q, expected %q", c.strs[i], s)
+		}
+	}
+	return nil
+}
+
+// collectExpect returns the collector results for the JSON document using
+// the encoding/json Decoder.
+func collectExpect(data []byte) *collector {
+	var c collector
+	if err := goCollect(data, &c); err != nil {
+		log.Fatalf("Failed to collect from %s. %s\n", filename, err)
+	}
+	return &c
+}
+
+// goCollect feeds the tokens from a json.Decoder to the collector. The
+// Decoder does not distinguish between keys and string values so a stack of
+// containers is kept to track when a key is expected.
+func goCollect(data []byte, c *collector) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []bool // true for objects
+	expectKey := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch tt := tok.(type) {
+		case json.Delim:
+			switch tt {
+			case '{':
+				c.start()
+				stack = append(stack, true)
+				expectKey = true
+				continue
+			case '[':
+				c.start()
+				stack = append(stack, false)
+				expectKey = false
+				continue
+			default:
+				c.end()
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if expectKey {
+				c.key(tt)
+				expectKey = false
+				continue
+			}
+			c.str(tt)
+		case float64:
+			c.num(tt)
+		}
+		expectKey = 0 < len(stack) && stack[len(stack)-1]
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+)
+
+// convertPatient returns the sample unmarshalled into a Patient by
+// encoding/json. It is the expected result of recomposing the sample.
+func convertPatient() *Patient {
+	sample, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Fatalf("Failed to read %s. %s\n", filename, err)
+	}
+	var patient Patient
+	if err = json.Unmarshal(sample, &patient); err != nil {
+		log.Fatalf("Failed to unmarshal %s. %s\n", filename, err)
+	}
+	return &patient
+}
+
+// decomposeCheck returns an error if simple types decomposed from a Patient
+// are not the same as the encoding/json form of the Patient. Nulls and empty
+// lists are both dropped before comparing since alt.Decompose converts nil
+// slices to empty lists while encoding/json writes them as null.
+func decomposeCheck(v interface{}, expect *Patient) error {
+	var got, want interface{}
+	for _, pair := range []struct {
+		src interface{}
+		dst *interface{}
+	}{{src: v, dst: &got}, {src: expect, dst: &want}} {
+		j, err := json.Marshal(pair.src)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(j, pair.dst); err != nil {
+			return err
+		}
+	}
+	if !reflect.DeepEqual(pruneEmpty(got), pruneEmpty(want)) {
+		return errors.New("decomposed Patient is not the same as the original")
+	}
+	return nil
+}
+
+// pruneEmpty removes null and empty list members from the objects in
+// generic data.
+func pruneEmpty(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case []interface{}:
+		for i, m := range tv {
+			tv[i] = pruneEmpty(m)
+		}
+	case map[string]interface{}:
+		for k, m := range tv {
+			if list, ok := m.([]interface{}); m == nil || (ok && len(list) == 0) {
+				delete(tv, k)
+			} else {
+				tv[k] = pruneEmpty(m)
+			}
+		}
+	}
+	return v
+}
+
+// recomposeCheck returns an error if a recomposed Patient is not the same as
+// the expected Patient.
+func recomposeCheck(patient, expect *Patient) error {
+	if !reflect.DeepEqual(patient, expect) {
+		return errors.New("converted Patient is not the same as the original")
+	}
+	return nil
+}
+
+// benchDecompose checks and then benchmarks converting a Patient to simple
+// types.
+func benchDecompose(b *testing.B, decompose func(patient *Patient) (interface{}, error)) {
+	patient := convertPatient()
+	v, err := decompose(patient)
+	if err == nil {
+		err = decomposeCheck(v, patient)
+	}
+	if err != nil {
+		benchErr = err
+		b.Fail()
+		return
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, benchErr = decompose(patient); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
+
+// benchRecompose checks and then benchmarks converting simple types to a
+// Patient.
+func benchRecompose(b *testing.B, recompose func(v interface{}) (*Patient, error)) {
+	data := loadSample()
+	patient, err := recompose(data)
+	if err == nil {
+		err = recomposeCheck(patient, convertPatient())
+	}
+	if err != nil {
+		benchErr = err
+		b.Fail()
+		return
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, benchErr = recompose(data); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
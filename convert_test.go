@@ -0,0 +1,36 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+)
+
+func TestDecomposeCheck(t *testing.T) {
+	patient := convertPatient()
+	if err := decomposeCheck(alt.Decompose(patient, &ojg.GoOptions), patient); err != nil {
+		t.Errorf("alt.Decompose check failed. %s", err)
+	}
+	v := alt.Decompose(patient, &ojg.GoOptions)
+	v.(map[string]interface{})["Gender"] = "unknown"
+	if err := decomposeCheck(v, patient); err == nil {
+		t.Errorf("changed gender not detected")
+	}
+}
+
+func TestRecomposeCheck(t *testing.T) {
+	var patient Patient
+	if _, err := alt.Recompose(loadSample(), &patient); err != nil {
+		t.Fatalf("alt.Recompose failed. %s", err)
+	}
+	if err := recomposeCheck(&patient, convertPatient()); err != nil {
+		t.Errorf("alt.Recompose check failed. %s", err)
+	}
+	patient.Active = !patient.Active
+	if err := recomposeCheck(&patient, convertPatient()); err == nil {
+		t.Errorf("changed active not detected")
+	}
+}
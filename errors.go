@@ -0,0 +1,154 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// errorDoc is a malformed document. The error starts at the first byte of
+// after or at the end of the document if after is empty.
+type errorDoc struct {
+	name   string
+	before string
+	after  string
+}
+
+func (ed *errorDoc) data() []byte {
+	return []byte(ed.before + ed.after)
+}
+
+func (ed *errorDoc) offset() int {
+	return len(ed.before)
+}
+
+// errorDocs returns the malformed documents used to check error positions.
+// The last is the sample file with an error in the middle.
+func errorDocs() []*errorDoc {
+	sample, offset := errorSample()
+	return []*errorDoc{
+		{name: "bad value", before: "{\"a\":1,\n \"b\": ", after: "x}"},
+		{name: "extra comma", before: "[1,2,", after: ",3]"},
+		{name: "trailing comma", before: "[\n  1,\n  2,\n  3,\n", after: "]"},
+		{name: "missing colon", before: `{"a" `, after: `1}`},
+		{name: "mismatched close", before: `{"a":[1,2`, after: `}`},
+		{name: "bad escape", before: `"abc\`, after: `q"`},
+		{name: "control character", before: `{"a":"line`, after: "\nbreak\"}"},
+		{name: "bad number", before: `[1.`, after: `e5]`},
+		{name: "bad literal", before: `{"name":"` + strings.Repeat("x", 60) + "\",\n  \"flag\": tru", after: "}"},
+		{name: "trailing garbage", before: `{"a":1} `, after: "x"},
+		{name: "unclosed array", before: "[1, 2\n\n"},
+		{name: "sample", before: string(sample[:offset]), after: string(sample[offset:])},
+	}
+}
+
+// errorSample returns the sample with an x inserted at the start of the line
+// in the middle of the document along with the offset of the x.
+func errorSample() ([]byte, int) {
+	sample, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Fatalf("Failed to read %s. %s\n", filename, err)
+	}
+	i := len(sample)/2 + bytes.IndexByte(sample[len(sample)/2:], '\n') + 1
+	return append(append(append([]byte{}, sample[:i]...), 'x'), sample[i:]...), i
+}
+
+// lineColumnOffset converts a one based line and column to a byte offset.
+func lineColumnOffset(data []byte, line, column int) int {
+	offset := 0
+	for ; 1 < line; line-- {
+		i := bytes.IndexByte(data[offset:], '\n')
+		if i < 0 {
+			return -1
+		}
+		offset += i + 1
+	}
+	return offset + column - 1
+}
+
+// safeParse calls the package parse function and turns a panic into an error
+// so one misbehaving package does not stop the check.
+func safeParse(p *pkg, data []byte) (v interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return p.parse(data)
+}
+
+// errorReport parses each malformed document with each package and displays
+// how close the position in the error is to the true offset of the error
+// along with a summary and a sample message for each package.
+func errorReport(pkgs []*pkg) {
+	docs := errorDocs()
+	var rows [][]string
+	exact := map[string]int{}
+	near := map[string]int{}
+	missing := map[string]int{}
+	accepted := map[string]int{}
+	samples := map[string]string{}
+	for _, ed := range docs {
+		first := ed.name
+		for _, p := range pkgs {
+			if p.parse == nil {
+				continue
+			}
+			row := []string{first, p.name, strconv.Itoa(ed.offset())}
+			first = ""
+			data := ed.data()
+			_, err := safeParse(p, data)
+			switch {
+			case err == nil:
+				accepted[p.name]++
+				rows = append(rows, append(row, "accepted", ""))
+				continue
+			case len(samples[p.name]) == 0:
+				samples[p.name] = clip(strings.ReplaceAll(err.Error(), "\n", `\n`))
+			}
+			pos := -1
+			if p.errorOffset != nil {
+				pos = p.errorOffset(data, err)
+			}
+			if pos < 0 {
+				missing[p.name]++
+				rows = append(rows, append(row, "none", ""))
+				continue
+			}
+			delta := pos - ed.offset()
+			switch {
+			case delta == 0:
+				exact[p.name]++
+			case -2 <= delta && delta <= 2:
+				near[p.name]++
+			}
+			rows = append(rows, append(row, strconv.Itoa(pos), fmt.Sprintf("%+d", delta)))
+		}
+	}
+	fmt.Println()
+	fmt.Println("Error position reported for malformed documents")
+	printTable([]string{"document", "package", "offset", "reported", "delta"}, rows)
+
+	rows = nil
+	for _, p := range pkgs {
+		if p.parse == nil {
+			continue
+		}
+		rows = append(rows, []string{
+			p.name,
+			fmt.Sprintf("%d/%d", exact[p.name], len(docs)),
+			strconv.Itoa(near[p.name]),
+			strconv.Itoa(missing[p.name]),
+			strconv.Itoa(accepted[p.name]),
+			samples[p.name],
+		})
+	}
+	fmt.Println()
+	fmt.Println("Error position accuracy (near is within 2 bytes)")
+	printTable([]string{"package", "exact", "near", "none", "accepted", "sample message"}, rows)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineColumnOffset(t *testing.T) {
+	data := []byte("[\n  1,\n  x]")
+	for _, tc := range []struct {
+		line   int
+		column int
+		expect int
+	}{
+		{line: 1, column: 1, expect: 0},
+		{line: 2, column: 3, expect: 4},
+		{line: 3, column: 3, expect: 9},
+		{line: 4, column: 1, expect: -1},
+	} {
+		if offset := lineColumnOffset(data, tc.line, tc.column); offset != tc.expect {
+			t.Errorf("%d:%d expected offset %d, got %d", tc.line, tc.column, tc.expect, offset)
+		}
+	}
+}
+
+func TestErrorOffset(t *testing.T) {
+	docs := []*errorDoc{
+		{name: "short", before: "{\"a\":1,\n \"b\": ", after: "x}"},
+		{name: "long", before: `{"a":"` + strings.Repeat("y", 100) + `",` + "\n" + `"b": `, after: "x" + strings.Repeat(" ", 100) + "}"},
+	}
+	for _, p := range packages {
+		if p.errorOffset == nil {
+			continue
+		}
+		for _, ed := range docs {
+			_, err := p.parse(ed.data())
+			if err == nil {
+				t.Fatalf("%s did not return an error for %s", p.name, ed.name)
+			}
+			if offset := p.errorOffset(ed.data(), err); offset < ed.offset()-2 || ed.offset()+2 < offset {
+				t.Errorf("%s expected an offset near %d for %s, got %d from %s", p.name, ed.offset(), ed.name, offset, err)
+			}
+		}
+	}
+}
+
+func TestErrorSample(t *testing.T) {
+	sample, offset := errorSample()
+	if sample[offset] != 'x' || sample[offset-1] != '\n' {
+		t.Errorf("expected an x at the start of a line at %d", offset)
+	}
+}
+
+func TestErrorReportPanic(t *testing.T) {
+	p := &pkg{
+		name: "panicky",
+		parse: func(data []byte) (interface{}, error) {
+			panic("malformed")
+		},
+	}
+	out := captureOutput(t, func() { errorReport([]*pkg{p}) })
+	if !strings.Contains(out, "panic: malformed") {
+		t.Errorf("expected the panic in the report, got %s", out)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// escapeCases are strings that are written differently depending on whether
+// HTML characters and non-ASCII characters are escaped.
+var escapeCases = []string{
+	`<a href="/x?a=1&b=2">link</a>`,
+	"café",
+	"日本語",
+	"party 🎉",
+	"line\u2028separator\u2029",
+	"tab\tand\x01control",
+	"bad \xff utf-8",
+	"<é & 🎉>",
+}
+
+// escapeMode is a combination of escape options.
+type escapeMode struct {
+	name  string
+	html  bool
+	ascii bool
+}
+
+var escapeModes = []escapeMode{
+	{name: "html", html: true},
+	{name: "no html"},
+	{name: "html+ascii", html: true, ascii: true},
+	{name: "ascii", ascii: true},
+}
+
+// escapeExpect returns the expected JSON for a string. As with encoding/json,
+// control characters and the line and paragraph separators are always
+// escaped and invalid UTF-8 is replaced with U+FFFD. Escapes use lowercase
+// hex digits.
+func escapeExpect(s string, html, ascii bool) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < 0x20,
+			html && (r == '<' || r == '>' || r == '&'),
+			r == '\u2028' || r == '\u2029':
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		case ascii && utf8.RuneSelf <= r:
+			sb.WriteString(asciiRune(r))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// asciiRune returns the \u escape for a rune using a surrogate pair for runes
+// outside the basic multilingual plane.
+func asciiRune(r rune) string {
+	if r <= 0xffff {
+		return fmt.Sprintf(`\u%04x`, r)
+	}
+	r -= 0x10000
+	return fmt.Sprintf(`\u%04x\u%04x`, 0xd800+(r>>10), 0xdc00+(r&0x3ff))
+}
+
+// escapeMarshal marshals with the package for the mode. An error is returned
+// if the package does not have an option for the mode.
+func escapeMarshal(p *pkg, mode escapeMode, v interface{}) ([]byte, error) {
+	marshal := p.marshalEscape(mode.html, mode.ascii)
+	if marshal == nil {
+		return nil, errors.New("not supported")
+	}
+	return marshal(v)
+}
+
+// escapeSample returns a string heavy sample with a mix of plain text, HTML
+// characters, and non-ASCII characters.
+func escapeSample() interface{} {
+	list := make([]interface{}, 500)
+	for i := range list {
+		list[i] = map[string]interface{}{
+			"id":    strconv.Itoa(i),
+			"title": fmt.Sprintf("Item %d of the sample list", i),
+			"html":  fmt.Sprintf(`<p class="item">Item %d &amp; more</p>`, i),
+			"text":  fmt.Sprintf("Café %d ünïcödé 日本語 🎉", i),
+			"tags":  []interface{}{"plain", "<tag>", "naïve", "a&b"},
+		}
+	}
+	return list
+}
+
+// benchMarshalEscape marshals the escape sample with a marshal function
+// returned by the package marshalEscape for the suite mode. The output is
+// checked against the encoding/json output with the same HTML escaping before
+// timing starts.
+func benchMarshalEscape(b *testing.B, marshal func(v interface{}) ([]byte, error), escapeHTML bool) {
+	data := escapeSample()
+	out, err := marshal(data)
+	if err == nil {
+		var expect []byte
+		if expect, err = goMarshalEscape(escapeHTML, false)(data); err == nil && !bytes.Equal(out, expect) {
+			err = fmt.Errorf("escaped output differs from encoding/json")
+		}
+	}
+	if err != nil {
+		benchErr = err
+		b.Fail()
+		return
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, benchErr = marshal(data); benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+// escapeReport marshals each escape case with each package in each mode and
+// displays whether the output matches the expected form. The default column
+// shows which mode the package marshal function matches.
+func escapeReport(pkgs []*pkg) {
+	header := []string{"string", "package", "default"}
+	for _, mode := range escapeModes {
+		header = append(header, mode.name)
+	}
+	var rows [][]string
+	for _, s := range escapeCases {
+		first := strconv.QuoteToASCII(s)
+		for _, p := range pkgs {
+			if p.marshalEscape == nil {
+				continue
+			}
+			row := []string{first, p.name, escapeDefault(p, s)}
+			first = ""
+			for _, mode := range escapeModes {
+				out, err := escapeMarshal(p, mode, s)
+				switch {
+				case err != nil:
+					row = append(row, clip(err.Error()))
+				case string(out) == escapeExpect(s, mode.html, mode.ascii):
+					row = append(row, "ok")
+				default:
+					row = append(row, escapeShow(out))
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	fmt.Println()
+	fmt.Println("String escaping when marshalling")
+	printTable(header, rows)
+}
+
+// escapeDefault returns the modes the package marshal function matches for a
+// string or the output if it matches none.
+func escapeDefault(p *pkg, s string) string {
+	out, err := p.marshal(s)
+	if err != nil {
+		return clip(err.Error())
+	}
+	var matches []string
+	for _, mode := range escapeModes {
+		if string(out) == escapeExpect(s, mode.html, mode.ascii) {
+			matches = append(matches, mode.name)
+		}
+	}
+	if len(matches) == 0 {
+		return escapeShow(out)
+	}
+	return strings.Join(matches, "/")
+}
+
+// escapeShow returns the output as a go quoted string so that invisible and
+// invalid characters can be seen in a table.
+func escapeShow(out []byte) string {
+	s := string(out)
+	if 2 <= len(s) && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return clip(strconv.QuoteToASCII(s))
+}
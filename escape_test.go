@@ -0,0 +1,44 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import "testing"
+
+func TestEscapeExpect(t *testing.T) {
+	for _, tc := range []struct {
+		s      string
+		html   bool
+		ascii  bool
+		expect string
+	}{
+		{s: "<a&b>", html: true, expect: `"\u003ca\u0026b\u003e"`},
+		{s: "<a&b>", expect: `"<a&b>"`},
+		{s: "caf\xc3\xa9", expect: "\"caf\xc3\xa9\""},
+		{s: "caf\xc3\xa9", ascii: true, expect: `"caf\u00e9"`},
+		{s: "\U0001f389", ascii: true, expect: `"\ud83c\udf89"`},
+		{s: "a\xe2\x80\xa8b", expect: `"a\u2028b"`},
+		{s: "\"\\\n\x01", expect: `"\"\\\n\u0001"`},
+		{s: "\xff", expect: "\"\xef\xbf\xbd\""},
+		{s: "\xff", ascii: true, expect: `"\ufffd"`},
+	} {
+		if got := escapeExpect(tc.s, tc.html, tc.ascii); got != tc.expect {
+			t.Errorf("%q html: %t ascii: %t expected %s, got %s", tc.s, tc.html, tc.ascii, tc.expect, got)
+		}
+	}
+}
+
+func TestEscapeMarshalNotSupported(t *testing.T) {
+	for _, p := range packages {
+		if p.marshalEscape == nil {
+			continue
+		}
+		for _, mode := range escapeModes {
+			if !mode.ascii {
+				continue
+			}
+			if _, err := escapeMarshal(p, mode, "café"); err == nil || err.Error() != "not supported" {
+				t.Errorf("%s %s expected not supported, got %v", p.name, mode.name, err)
+			}
+		}
+	}
+}
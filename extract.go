@@ -0,0 +1,198 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+)
+
+// patientPaths are the paths to the values pulled from the sample by the
+// extract-fields suite. Path elements are either a string key or an int
+// array index.
+var patientPaths = [][]interface{}{
+	{"id"},
+	{"name", 0, "family"},
+	{"address", 0, "city"},
+}
+
+// logPaths are the paths to the values pulled from each entry in the log
+// file by the extract-log suite.
+var logPaths = [][]interface{}{
+	{"who"},
+	{"level"},
+	{"where", 0, "file"},
+}
+
+// extractor pulls the values at a fixed set of paths from a JSON document
+// into got.
+type extractor func(data []byte, got []string) error
+
+// patientFields is a struct with only the fields pulled from the sample by
+// the extract-fields suite.
+type patientFields struct {
+	ID   string
+	Name []struct {
+		Family string
+	}
+	Address []struct {
+		City string
+	}
+}
+
+func (pf *patientFields) values(got []string) {
+	got[0] = pf.ID
+	if 0 < len(pf.Name) {
+		got[1] = pf.Name[0].Family
+	}
+	if 0 < len(pf.Address) {
+		got[2] = pf.Address[0].City
+	}
+}
+
+// logFields is a struct with only the fields pulled from a log entry by the
+// extract-log suite.
+type logFields struct {
+	Who   string
+	Level string
+	Where []struct {
+		File string
+	}
+}
+
+func (lf *logFields) values(got []string) {
+	got[0] = lf.Who
+	got[1] = lf.Level
+	if 0 < len(lf.Where) {
+		got[2] = lf.Where[0].File
+	}
+}
+
+// benchExtractFields runs the extractor on the sample.
+func benchExtractFields(b *testing.B, x extractor) {
+	sample, _ := ioutil.ReadFile(filename)
+	expect := extractExpect(sample, patientPaths)
+	got := make([]string, len(expect))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if benchErr = x(sample, got); benchErr == nil {
+			benchErr = extractCheck(got, expect)
+		}
+		if benchErr != nil {
+			b.Fail()
+			break
+		}
+	}
+}
+
+// benchExtractLog runs the extractor on each entry in the large log file.
+func benchExtractLog(b *testing.B, x extractor) {
+	f := openLargeLogFile()
+	defer func() { _ = f.Close() }()
+	expect := logExpect(f)
+	got := make([]string, len(expect))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, _ = f.Seek(0, 0)
+		if benchErr = eachLine(f, func(line []byte) error {
+			if err := x(line, got); err != nil {
+				return err
+			}
+			return extractCheck(got, expect)
+		}); benchErr != nil {
+			b.Fail()
+			break
+		}
+	}
+}
+
+// pathExpr converts a path to a JSONPath expression.
+func pathExpr(path []interface{}) (x jp.Expr) {
+	for _, p := range path {
+		switch tp := p.(type) {
+		case string:
+			x = x.C(tp)
+		case int:
+			x = x.N(tp)
+		}
+	}
+	return
+}
+
+// pathKeys converts a path to a slice of strings with array indexes
+// converted to strings.
+func pathKeys(path []interface{}) (keys []string) {
+	for _, p := range path {
+		switch tp := p.(type) {
+		case string:
+			keys = append(keys, tp)
+		case int:
+			keys = append(keys, strconv.Itoa(tp))
+		}
+	}
+	return
+}
+
+// extractExpect returns the values at each path in the JSON document using a
+// full parse so extractions can be verified.
+func extractExpect(data []byte, paths [][]interface{}) []string {
+	v, err := oj.Parse(data)
+	if err != nil {
+		log.Fatalf("Failed to parse expected values. %s\n", err)
+	}
+	expect := make([]string, len(paths))
+	for i, path := range paths {
+		expect[i] = alt.String(pathExpr(path).First(v))
+	}
+	return expect
+}
+
+// extractCheck returns an error if the extracted values do not match the
+// expected values.
+func extractCheck(got, expect []string) error {
+	for i, s := range expect {
+		if got[i] != s {
+			return fmt.Errorf("extracted %q, expected %q", got, expect)
+		}
+	}
+	return nil
+}
+
+// logExpect returns the expected values for the first entry in the log
+// file and then rewinds the file.
+func logExpect(f *os.File) (expect []string) {
+	_ = eachLine(f, func(line []byte) error {
+		expect = extractExpect(line, logPaths)
+		return io.EOF
+	})
+	_, _ = f.Seek(0, 0)
+	return
+}
+
+// eachLine calls cb with each non-empty line read from r until the end of
+// the reader or cb returns an error. The line is only valid until the next
+// call to cb.
+func eachLine(r io.Reader, cb func(line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := cb(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
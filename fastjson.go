@@ -3,17 +3,349 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/valyala/fastjson"
 )
 
 var fastjsonPkg = pkg{
-	name: "fastjson",
+	name:   "fastjson",
+	module: "github.com/valyala/fastjson",
 	calls: map[string]*call{
-		"validate": {name: "Validate", fun: fastjsonValidate},
+		"parse-error":    {name: "ParseBytes", fun: fastjsonParseError},
+		"validate":       {name: "Validate", fun: fastjsonValidate},
+		"decode":         {name: "Scanner", fun: fastjsonDecode},
+		"build":          {name: "Arena", fun: fastjsonBuild},
+		"modify":         {name: "Value.Set/Del", fun: fastjsonModify},
+		"extract-fields": {name: "Get", fun: fastjsonExtractFields},
+		"extract-log":    {name: "Get", fun: fastjsonExtractLog},
 	},
+	parse: func(data []byte) (interface{}, error) {
+		v, err := fastjson.ParseBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return fastjsonSimple(v), nil
+	},
+	validate:    fastjson.ValidateBytes,
+	errorOffset: fastjsonErrorOffset,
+}
+
+// fastjsonSimple converts a fastjson.Value to simple types since fastjson does
+// not provide a conversion. Numbers are int64 if they fit otherwise float64.
+func fastjsonSimple(v *fastjson.Value) interface{} {
+	switch v.Type() {
+	case fastjson.TypeObject:
+		obj, _ := v.Object()
+		m := map[string]interface{}{}
+		obj.Visit(func(k []byte, mv *fastjson.Value) {
+			m[string(k)] = fastjsonSimple(mv)
+		})
+		return m
+	case fastjson.TypeArray:
+		list, _ := v.Array()
+		a := make([]interface{}, len(list))
+		for i, av := range list {
+			a[i] = fastjsonSimple(av)
+		}
+		return a
+	case fastjson.TypeString:
+		s, _ := v.StringBytes()
+		return string(s)
+	case fastjson.TypeNumber:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case fastjson.TypeTrue:
+		return true
+	case fastjson.TypeFalse:
+		return false
+	}
+	return nil
+}
+
+// fastjsonErrorOffset finds the offset of the unparsed tail included in the
+// error message. Tails longer than 80 bytes are shortened to the first and
+// last 40 bytes.
+func fastjsonErrorOffset(data []byte, err error) int {
+	msg := err.Error()
+	i := strings.LastIndex(msg, " tail: ")
+	if i < 0 {
+		return -1
+	}
+	tail, uerr := strconv.Unquote(msg[i+len(" tail: "):])
+	if uerr != nil {
+		return -1
+	}
+	if len(tail) <= 80 {
+		if bytes.HasSuffix(data, []byte(tail)) {
+			return len(data) - len(tail)
+		}
+		return -1
+	}
+	start := []byte(tail[:40])
+	for j := 0; j < len(data)-80; j++ {
+		if bytes.HasPrefix(data[j:], start) {
+			return j
+		}
+	}
+	return -1
+}
+
+func fastjsonParseError(b *testing.B) {
+	sample, _ := errorSample()
+	b.ResetTimer()
+
+	var p fastjson.Parser
+	for n := 0; n < b.N; n++ {
+		if _, err := p.ParseBytes(sample); err == nil {
+			benchErr = errors.New("error not detected")
+			b.Fail()
+		}
+	}
+}
+
+// fastjsonBuild builds the build document with values from an Arena. Object
+// members are written in the order they are set.
+func fastjsonBuild(b *testing.B) {
+	var a fastjson.Arena
+	var buf []byte
+	benchBuild(b, func() []byte {
+		a.Reset()
+		doc := a.NewObject()
+
+		ext := a.NewObject()
+		ext.Set("url", a.NewString("http://hl7.org/fhir/StructureDefinition/patient-birthTime"))
+		ext.Set("valueDateTime", a.NewString("1974-12-25T14:35:45-05:00"))
+		list := a.NewArray()
+		list.SetArrayItem(0, ext)
+		obj := a.NewObject()
+		obj.Set("extension", list)
+		doc.Set("_birthDate", obj)
+		doc.Set("active", a.NewTrue())
+
+		addr := a.NewObject()
+		addr.Set("city", a.NewString("PleasantVille"))
+		addr.Set("district", a.NewString("Rainbow"))
+		list = a.NewArray()
+		list.SetArrayItem(0, a.NewString("534 Erewhon St"))
+		addr.Set("line", list)
+		obj = a.NewObject()
+		obj.Set("start", a.NewString("1974-12-25"))
+		addr.Set("period", obj)
+		addr.Set("postalCode", a.NewString("3999"))
+		addr.Set("state", a.NewString("Vic"))
+		addr.Set("text", a.NewString("534 Erewhon St PeasantVille, Rainbow, Vic  3999"))
+		addr.Set("type", a.NewString("both"))
+		addr.Set("use", a.NewString("home"))
+		list = a.NewArray()
+		list.SetArrayItem(0, addr)
+		doc.Set("address", list)
+		doc.Set("birthDate", a.NewString("1974-12-25"))
+
+		contact := a.NewObject()
+		addr = a.NewObject()
+		addr.Set("city", a.NewString("PleasantVille"))
+		addr.Set("district", a.NewString("Rainbow"))
+		list = a.NewArray()
+		list.SetArrayItem(0, a.NewString("534 Erewhon St"))
+		addr.Set("line", list)
+		obj = a.NewObject()
+		obj.Set("start", a.NewString("1974-12-25"))
+		addr.Set("period", obj)
+		addr.Set("postalCode", a.NewString("3999"))
+		addr.Set("state", a.NewString("Vic"))
+		addr.Set("type", a.NewString("both"))
+		addr.Set("use", a.NewString("home"))
+		contact.Set("address", addr)
+		contact.Set("gender", a.NewString("female"))
+		name := a.NewObject()
+		ext = a.NewObject()
+		ext.Set("url", a.NewString("http://hl7.org/fhir/StructureDefinition/humanname-own-prefix"))
+		ext.Set("valueString", a.NewString("VV"))
+		list = a.NewArray()
+		list.SetArrayItem(0, ext)
+		obj = a.NewObject()
+		obj.Set("extension", list)
+		name.Set("_family", obj)
+		name.Set("family", a.NewString("du Marché"))
+		list = a.NewArray()
+		list.SetArrayItem(0, a.NewString("Bénédicte"))
+		name.Set("given", list)
+		contact.Set("name", name)
+		obj = a.NewObject()
+		obj.Set("start", a.NewString("2012"))
+		contact.Set("period", obj)
+		code := a.NewObject()
+		code.Set("code", a.NewString("N"))
+		code.Set("system", a.NewString("http://terminology.hl7.org/CodeSystem/v2-0131"))
+		list = a.NewArray()
+		list.SetArrayItem(0, code)
+		obj = a.NewObject()
+		obj.Set("coding", list)
+		list = a.NewArray()
+		list.SetArrayItem(0, obj)
+		contact.Set("relationship", list)
+		tel := a.NewObject()
+		tel.Set("system", a.NewString("phone"))
+		tel.Set("value", a.NewString("+33 (237) 998327"))
+		list = a.NewArray()
+		list.SetArrayItem(0, tel)
+		contact.Set("telecom", list)
+		list = a.NewArray()
+		list.SetArrayItem(0, contact)
+		doc.Set("contact", list)
+		doc.Set("deceasedBoolean", a.NewFalse())
+		doc.Set("gender", a.NewString("male"))
+		doc.Set("id", a.NewString("example"))
+
+		ident := a.NewObject()
+		obj = a.NewObject()
+		obj.Set("display", a.NewString("Acme Healthcare"))
+		ident.Set("assigner", obj)
+		obj = a.NewObject()
+		obj.Set("start", a.NewString("2001-05-06"))
+		ident.Set("period", obj)
+		ident.Set("system", a.NewString("urn:oid:1.2.36.146.595.217.0.1"))
+		code = a.NewObject()
+		code.Set("code", a.NewString("MR"))
+		code.Set("system", a.NewString("http://terminology.hl7.org/CodeSystem/v2-0203"))
+		list = a.NewArray()
+		list.SetArrayItem(0, code)
+		obj = a.NewObject()
+		obj.Set("coding", list)
+		ident.Set("type", obj)
+		ident.Set("use", a.NewString("usual"))
+		ident.Set("value", a.NewString("12345"))
+		list = a.NewArray()
+		list.SetArrayItem(0, ident)
+		doc.Set("identifier", list)
+		obj = a.NewObject()
+		obj.Set("reference", a.NewString("Organization/1"))
+		doc.Set("managingOrganization", obj)
+
+		names := a.NewArray()
+		name = a.NewObject()
+		name.Set("family", a.NewString("Chalmers"))
+		list = a.NewArray()
+		list.SetArrayItem(0, a.NewString("Peter"))
+		list.SetArrayItem(1, a.NewString("James"))
+		name.Set("given", list)
+		name.Set("use", a.NewString("official"))
+		names.SetArrayItem(0, name)
+		name = a.NewObject()
+		list = a.NewArray()
+		list.SetArrayItem(0, a.NewString("Jim"))
+		name.Set("given", list)
+		name.Set("use", a.NewString("usual"))
+		names.SetArrayItem(1, name)
+		name = a.NewObject()
+		name.Set("family", a.NewString("Windsor"))
+		list = a.NewArray()
+		list.SetArrayItem(0, a.NewString("Peter"))
+		list.SetArrayItem(1, a.NewString("James"))
+		name.Set("given", list)
+		obj = a.NewObject()
+		obj.Set("end", a.NewString("2002"))
+		name.Set("period", obj)
+		name.Set("use", a.NewString("maiden"))
+		names.SetArrayItem(2, name)
+		doc.Set("name", names)
+		doc.Set("resourceType", a.NewString("Patient"))
+
+		tels := a.NewArray()
+		tel = a.NewObject()
+		tel.Set("use", a.NewString("home"))
+		tels.SetArrayItem(0, tel)
+		tel = a.NewObject()
+		tel.Set("rank", a.NewNumberInt(1))
+		tel.Set("system", a.NewString("phone"))
+		tel.Set("use", a.NewString("work"))
+		tel.Set("value", a.NewString("(03) 5555 6473"))
+		tels.SetArrayItem(1, tel)
+		tel = a.NewObject()
+		tel.Set("rank", a.NewNumberInt(2))
+		tel.Set("system", a.NewString("phone"))
+		tel.Set("use", a.NewString("mobile"))
+		tel.Set("value", a.NewString("(03) 3410 5613"))
+		tels.SetArrayItem(2, tel)
+		tel = a.NewObject()
+		obj = a.NewObject()
+		obj.Set("end", a.NewString("2014"))
+		tel.Set("period", obj)
+		tel.Set("system", a.NewString("phone"))
+		tel.Set("use", a.NewString("old"))
+		tel.Set("value", a.NewString("(03) 5555 8834"))
+		tels.SetArrayItem(3, tel)
+		doc.Set("telecom", tels)
+
+		obj = a.NewObject()
+		obj.Set("div", a.NewString(buildDiv))
+		obj.Set("status", a.NewString("generated"))
+		doc.Set("text", obj)
+
+		buf = doc.MarshalTo(buf[:0])
+		return buf
+	})
+}
+
+// fastjsonModify parses with a reused fastjson.Parser and then sets and
+// deletes members of the parent of each path. New values are allocated from
+// an arena that is reset on each pass.
+func fastjsonModify(b *testing.B) {
+	parents := make([][]string, len(modifyOps))
+	lasts := make([]string, len(modifyOps))
+	for i, op := range modifyOps {
+		keys := pathKeys(op.path)
+		parents[i] = keys[:len(keys)-1]
+		lasts[i] = keys[len(keys)-1]
+	}
+	var p fastjson.Parser
+	var a fastjson.Arena
+	var buf []byte
+	benchModify(b, func(data []byte) ([]byte, error) {
+		v, err := p.ParseBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		a.Reset()
+		for i, op := range modifyOps {
+			parent := v.Get(parents[i]...)
+			if parent == nil {
+				return nil, fmt.Errorf("%v not found", op.path)
+			}
+			if op.value == nil {
+				parent.Del(lasts[i])
+			} else {
+				parent.Set(lasts[i], fastjsonModifyValue(&a, op.value))
+			}
+		}
+		buf = v.MarshalTo(buf[:0])
+		return buf, nil
+	})
+}
+
+// fastjsonModifyValue returns an arena value for a modify value. Only strings
+// and booleans are used as modify values.
+func fastjsonModifyValue(a *fastjson.Arena, v interface{}) *fastjson.Value {
+	switch tv := v.(type) {
+	case string:
+		return a.NewString(tv)
+	case bool:
+		if tv {
+			return a.NewTrue()
+		}
+		return a.NewFalse()
+	}
+	return a.NewNull()
 }
 
 func fastjsonValidate(b *testing.B) {
@@ -26,3 +358,81 @@ func fastjsonValidate(b *testing.B) {
 		}
 	}
 }
+
+func fastjsonDecode(b *testing.B) {
+	sample, _ := ioutil.ReadFile(filename)
+	expect := tokenExpect(sample)
+	b.ResetTimer()
+
+	var sc fastjson.Scanner
+	for n := 0; n < b.N; n++ {
+		sc.InitBytes(sample)
+		cnt := 0
+		for sc.Next() {
+			cnt += fastjsonTokens(sc.Value())
+		}
+		if benchErr = sc.Error(); benchErr == nil {
+			benchErr = tokenCheck(cnt, expect)
+		}
+		if benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+// fastjsonTokens walks the value reading each value and returns the number
+// of tokens encountered.
+func fastjsonTokens(v *fastjson.Value) (cnt int) {
+	switch v.Type() {
+	case fastjson.TypeObject:
+		cnt += 2
+		obj, _ := v.Object()
+		obj.Visit(func(_ []byte, mv *fastjson.Value) {
+			cnt += 1 + fastjsonTokens(mv)
+		})
+	case fastjson.TypeArray:
+		cnt += 2
+		list, _ := v.Array()
+		for _, av := range list {
+			cnt += fastjsonTokens(av)
+		}
+	case fastjson.TypeString:
+		cnt++
+		_, _ = v.StringBytes()
+	case fastjson.TypeNumber:
+		cnt++
+		_, _ = v.Float64()
+	default:
+		cnt++
+	}
+	return
+}
+
+func fastjsonExtractFields(b *testing.B) {
+	benchExtractFields(b, fastjsonExtractor(patientPaths))
+}
+
+func fastjsonExtractLog(b *testing.B) {
+	benchExtractLog(b, fastjsonExtractor(logPaths))
+}
+
+// fastjsonExtractor parses with a reused fastjson.Parser and then gets each
+// value from the parsed result.
+func fastjsonExtractor(paths [][]interface{}) extractor {
+	keys := make([][]string, len(paths))
+	for i, path := range paths {
+		keys[i] = pathKeys(path)
+	}
+	var p fastjson.Parser
+	return func(data []byte, got []string) error {
+		v, err := p.ParseBytes(data)
+		if err != nil {
+			return err
+		}
+		for i, k := range keys {
+			got[i] = string(v.GetStringBytes(k...))
+		}
+		return nil
+	}
+}
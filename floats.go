@@ -0,0 +1,162 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// floatCorpus is a set of float64 values that packages format differently
+// or that are hard to format so they round trip exactly.
+var floatCorpus = []float64{
+	0,
+	math.Copysign(0, -1),
+	1,
+	-1.5,
+	0.1,
+	0.30000000000000004, // 0.1 + 0.2
+	1.0 / 3.0,
+	100,
+	123456789.125,
+	9007199254740992, // 2^53
+	1e20,
+	999999999999999900000, // largest float64 below 1e21
+	1e21,
+	1.2345678901234567e21,
+	1e-6,
+	9.99999999999999e-7,
+	1e-7,
+	2.2250738585072014e-308, // smallest normal
+	2.225073858507201e-308,  // largest subnormal
+	5e-324,                  // smallest subnormal
+	-5e-324,
+	1.7976931348623157e308, // largest float64
+	math.NaN(),
+	math.Inf(1),
+	math.Inf(-1),
+}
+
+// floatFormat returns the shortest representation of a float64 for display.
+func floatFormat(f float64) string {
+	if f == 0 && math.Signbit(f) {
+		return "-0"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// floatReport marshals each float in the corpus with each package and
+// displays the output along with whether it parses back to exactly the same
+// float64 and whether it is the same as the encoding/json output. NaN and
+// infinities are not valid JSON so an error is expected for them.
+func floatReport(pkgs []*pkg) {
+	var rows [][]string
+	for _, f := range floatCorpus {
+		expect, expectErr := json.Marshal(f)
+		first := floatFormat(f)
+		for _, p := range pkgs {
+			if p.marshal == nil {
+				continue
+			}
+			row := []string{first, p.name}
+			first = ""
+			out, err := safeMarshal(p, f)
+			switch {
+			case err != nil && expectErr != nil:
+				row = append(row, clip(err.Error()), "", "ok")
+			case err != nil:
+				row = append(row, clip(err.Error()), "", "error")
+			case expectErr != nil:
+				row = append(row, string(out), "", "no error")
+			default:
+				row = append(row, string(out), floatRoundTrip(f, out), fmt.Sprint(string(out) == string(expect)))
+			}
+			rows = append(rows, row)
+		}
+	}
+	fmt.Println()
+	fmt.Println("Float formatting when marshalling")
+	printTable([]string{"float", "package", "output", "round trip", "same as json"}, rows)
+}
+
+// floatRoundTrip returns "exact" if the JSON parses to the same bits as the
+// original float64 including the sign of zero.
+func floatRoundTrip(f float64, out []byte) string {
+	back, err := strconv.ParseFloat(string(out), 64)
+	switch {
+	case err != nil:
+		return clip(err.Error())
+	case math.Float64bits(back) == math.Float64bits(f):
+		return "exact"
+	case back == f:
+		return "sign lost"
+	}
+	return "changed to " + floatFormat(back)
+}
+
+// floatSampleSize is the number of values in the float heavy sample used by
+// the marshal-floats suite.
+const floatSampleSize = 1000
+
+// floatSample returns a list of finite floats from the corpus mixed with
+// floats spread across a wide range of magnitudes.
+func floatSample() []interface{} {
+	var finite []float64
+	for _, f := range floatCorpus {
+		if !math.IsNaN(f) && !math.IsInf(f, 0) {
+			finite = append(finite, f)
+		}
+	}
+	list := make([]interface{}, floatSampleSize)
+	for i := range list {
+		if i%2 == 0 {
+			list[i] = finite[(i/2)%len(finite)]
+		} else {
+			list[i] = math.Sqrt(float64(i)) * math.Pow10(i%41-20)
+		}
+	}
+	return list
+}
+
+// benchMarshalFloats marshals the float sample with the marshal function.
+// The output is checked to decode to the same values before timing starts.
+func benchMarshalFloats(b *testing.B, marshal func(v interface{}) ([]byte, error)) {
+	data := floatSample()
+	out, err := marshal(data)
+	if err == nil {
+		err = floatCheck(out, data)
+	}
+	if err != nil {
+		benchErr = err
+		b.Fail()
+		return
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, benchErr = marshal(data); benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+// floatCheck returns an error if the marshalled float sample does not decode
+// to the same values as the sample.
+func floatCheck(out []byte, data []interface{}) error {
+	var back []float64
+	if err := json.Unmarshal(out, &back); err != nil {
+		return err
+	}
+	if len(back) != len(data) {
+		return fmt.Errorf("expected %d floats, got %d", len(data), len(back))
+	}
+	for i, f := range back {
+		if f != data[i].(float64) {
+			return fmt.Errorf("float %d changed from %s to %s", i, floatFormat(data[i].(float64)), floatFormat(f))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloatRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		f      float64
+		out    string
+		expect string
+	}{
+		{f: 0.1, out: "0.1", expect: "exact"},
+		{f: 5e-324, out: "5e-324", expect: "exact"},
+		{f: math.Copysign(0, -1), out: "-0", expect: "exact"},
+		{f: math.Copysign(0, -1), out: "0", expect: "sign lost"},
+		{f: 0.30000000000000004, out: "0.3", expect: "changed to 0.3"},
+	} {
+		if got := floatRoundTrip(tc.f, []byte(tc.out)); got != tc.expect {
+			t.Errorf("%s for %g expected %s, got %s", tc.out, tc.f, tc.expect, got)
+		}
+	}
+}
+
+func TestFloatSample(t *testing.T) {
+	sample := floatSample()
+	if len(sample) != floatSampleSize {
+		t.Fatalf("expected %d floats, got %d", floatSampleSize, len(sample))
+	}
+	for i, v := range sample {
+		if f := v.(float64); math.IsNaN(f) || math.IsInf(f, 0) {
+			t.Errorf("sample %d is %g", i, f)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fuzzCorpusDir holds the seed inputs for FuzzParse in the go test fuzz
+// corpus format so go test loads them. When go test -fuzz=FuzzParse finds an
+// input that does not match its expected outcome it writes the input here.
+// Once the divergence has been checked, run with -fuzz-update to record the
+// current outcome of every seed input as the expected outcome.
+var fuzzCorpusDir = "testdata/fuzz/FuzzParse"
+
+// fuzzExpectFile holds the expected divergence of each seed input keyed by
+// the fuzzKey of the input. An empty divergence means all packages agree
+// with json. Inputs not in the file are expected to have no divergence.
+var fuzzExpectFile = "testdata/fuzz/expect.json"
+
+// outcome is the result of a parse and validate by a single package.
+type outcome struct {
+	value    interface{}
+	parseErr error
+	validErr error
+	panicked bool
+	hung     bool
+}
+
+// fuzzTimeout is how long a package is given to parse and validate an input
+// before it is considered hung.
+var fuzzTimeout = time.Second
+
+// divergence returns a description of how each package differs from the
+// reference package for the data or an empty string if all agree. Numbers are
+// compared as float64 so only values that differ after conversion to a
+// float64 are reported.
+func divergence(pkgs []*pkg, ref string, data []byte) string {
+	var expect *outcome
+	for _, p := range pkgs {
+		if p.name == ref {
+			expect = fuzzRun(p, data)
+		}
+	}
+	if expect == nil {
+		return ""
+	}
+	var diffs []string
+	valid := expect.validErr == nil
+	if (expect.parseErr == nil) != valid {
+		diffs = append(diffs, ref+":inconsistent")
+	}
+	for _, p := range pkgs {
+		if p.name == ref || p.parse == nil {
+			continue
+		}
+		o := fuzzRun(p, data)
+		switch {
+		case o.panicked:
+			diffs = append(diffs, p.name+":panic")
+			continue
+		case o.hung:
+			diffs = append(diffs, p.name+":hang")
+			continue
+		case p.validate == nil:
+		case o.validErr == nil && !valid:
+			diffs = append(diffs, p.name+":accepts")
+		case o.validErr != nil && valid:
+			diffs = append(diffs, p.name+":rejects")
+		}
+		switch {
+		case o.parseErr == nil && !valid:
+			diffs = append(diffs, p.name+":parse-accepts")
+		case o.parseErr != nil && valid:
+			diffs = append(diffs, p.name+":parse-rejects")
+		case o.parseErr == nil && expect.parseErr == nil &&
+			!reflect.DeepEqual(fuzzNormalize(o.value), fuzzNormalize(expect.value)):
+			diffs = append(diffs, p.name+":value")
+		}
+	}
+	sort.Strings(diffs)
+
+	return strings.Join(diffs, " ")
+}
+
+// fuzzRun parses and validates the data with a package. If the package does
+// not return before the fuzzTimeout the outcome is marked as hung and the
+// goroutine is abandoned.
+func fuzzRun(p *pkg, data []byte) *outcome {
+	done := make(chan *outcome, 1)
+	go func() {
+		o := &outcome{}
+		defer func() {
+			if r := recover(); r != nil {
+				o.panicked = true
+			}
+			done <- o
+		}()
+		// Some packages modify the input so each gets its own copy.
+		o.value, o.parseErr = p.parse(append([]byte{}, data...))
+		if p.validate != nil {
+			o.validErr = p.validate(append([]byte{}, data...))
+		}
+	}()
+	select {
+	case o := <-done:
+		return o
+	case <-time.After(fuzzTimeout):
+		return &outcome{hung: true}
+	}
+}
+
+// fuzzNormalize converts all numbers to float64 so values decoded as
+// different number types can be compared.
+func fuzzNormalize(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case int64:
+		return float64(tv)
+	case uint64:
+		return float64(tv)
+	case int:
+		return float64(tv)
+	case json.Number:
+		f, _ := strconv.ParseFloat(string(tv), 64)
+		return f
+	case []interface{}:
+		list := make([]interface{}, len(tv))
+		for i, m := range tv {
+			list[i] = fuzzNormalize(m)
+		}
+		return list
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(tv))
+		for k, m := range tv {
+			obj[k] = fuzzNormalize(m)
+		}
+		return obj
+	}
+	return v
+}
+
+// fuzzCorpus returns the inputs in the fuzz corpus directory keyed by file
+// name.
+func fuzzCorpus() map[string][]byte {
+	corpus := map[string][]byte{}
+	files, err := ioutil.ReadDir(fuzzCorpusDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return corpus
+		}
+		log.Fatalf("Failed to read %s. %s\n", fuzzCorpusDir, err)
+	}
+	for _, fi := range files {
+		if fi.IsDir() {
+			continue
+		}
+		path := filepath.Join(fuzzCorpusDir, fi.Name())
+		data, err := readFuzzFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read %s. %s\n", path, err)
+		}
+		corpus[fi.Name()] = data
+	}
+	return corpus
+}
+
+// fuzzSeeds returns the inputs FuzzParse adds to the corpus keyed by
+// fuzzKey. They are the sample and the number corpus.
+func fuzzSeeds() map[string][]byte {
+	seeds := map[string][]byte{}
+	if sample, err := ioutil.ReadFile(filename); err == nil {
+		seeds[fuzzKey(sample)] = sample
+	}
+	for _, num := range numberCorpus {
+		seeds[fuzzKey([]byte(num))] = []byte(num)
+	}
+	return seeds
+}
+
+// fuzzKey returns the key for an input in the expect file. It is the first
+// 16 hex digits of the SHA-256 of the input.
+func fuzzKey(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// readFuzzFile reads a go test fuzz corpus file with a single []byte value.
+func readFuzzFile(path string) ([]byte, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || lines[0] != "go test fuzz v1" ||
+		!strings.HasPrefix(lines[1], "[]byte(") || !strings.HasSuffix(lines[1], ")") {
+		return nil, fmt.Errorf("not a go test fuzz v1 file with a single []byte")
+	}
+	s, err := strconv.Unquote(lines[1][len("[]byte(") : len(lines[1])-1])
+	return []byte(s), err
+}
+
+// fuzzExpect returns the expected divergence of each input keyed by fuzzKey.
+func fuzzExpect() map[string]string {
+	expect := map[string]string{}
+	j, err := ioutil.ReadFile(fuzzExpectFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return expect
+		}
+		log.Fatalf("Failed to read %s. %s\n", fuzzExpectFile, err)
+	}
+	if err = json.Unmarshal(j, &expect); err != nil {
+		log.Fatalf("Failed to parse %s. %s\n", fuzzExpectFile, err)
+	}
+	return expect
+}
+
+// fuzzUpdate writes the current divergence of each corpus input and seed to
+// the expect file.
+func fuzzUpdate(pkgs []*pkg) {
+	expect := map[string]string{}
+	for _, inputs := range []map[string][]byte{fuzzCorpus(), fuzzSeeds()} {
+		for _, data := range inputs {
+			expect[fuzzKey(data)] = divergence(pkgs, "json", data)
+		}
+	}
+	// encoding/json writes map keys in sorted order so the file is stable.
+	j, err := json.MarshalIndent(expect, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(fuzzExpectFile, append(j, '\n'), 0644)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write %s. %s\n", fuzzExpectFile, err)
+	}
+	fmt.Printf("Wrote the expected outcome of %d inputs to %s\n", len(expect), fuzzExpectFile)
+}
+
+// fuzzReport displays the known divergences in the fuzz corpus grouped by
+// how the packages differ from the reference along with how many inputs do
+// not match the expected outcome.
+func fuzzReport(pkgs []*pkg) {
+	corpus := fuzzCorpus()
+	expect := fuzzExpect()
+	examples := map[string][]string{}
+	unexpected := map[string]int{}
+	for name, data := range corpus {
+		d := divergence(pkgs, "json", data)
+		if d != expect[fuzzKey(data)] {
+			unexpected[d]++
+		}
+		if len(d) == 0 {
+			d = "none"
+		}
+		examples[d] = append(examples[d], name)
+	}
+	var divs []string
+	for d, names := range examples {
+		sort.Strings(names)
+		divs = append(divs, d)
+	}
+	sort.Strings(divs)
+	var rows [][]string
+	for _, d := range divs {
+		names := examples[d]
+		key := d
+		if key == "none" {
+			key = ""
+		}
+		rows = append(rows, []string{
+			d,
+			strconv.Itoa(len(names)),
+			strconv.Itoa(unexpected[key]),
+			names[0],
+			clip(strconv.Quote(string(corpus[names[0]]))),
+		})
+	}
+	fmt.Println()
+	fmt.Printf("Known divergences from json in %s (%d inputs)\n", fuzzCorpusDir, len(corpus))
+	printTable([]string{"divergence", "inputs", "unexpected", "example", "input"}, rows)
+}
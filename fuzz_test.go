@@ -0,0 +1,23 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import "testing"
+
+// FuzzParse feeds the same input to the parse and validate functions of
+// every package and fails if the way they diverge from json is not the
+// expected outcome recorded for the input in the expect file. Inputs that
+// are not in the expect file, such as those generated while fuzzing, are
+// expected to have no divergence. The corpus in testdata/fuzz/FuzzParse is
+// loaded by go test.
+func FuzzParse(f *testing.F) {
+	expect := fuzzExpect()
+	for _, data := range fuzzSeeds() {
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if d := divergence(packages, "json", data); d != expect[fuzzKey(data)] {
+			t.Errorf("divergence %q, expected %q for %q", d, expect[fuzzKey(data)], data)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"testing"
+	"time"
+)
+
+// gcStats are the garbage collector costs of the last run of a benchmark.
+type gcStats struct {
+	Cycles float64       // automatic GC cycles per op
+	Pause  time.Duration // total stop the world pause time
+	Growth int64         // increase in heap bytes allocated and not yet freed
+}
+
+// measureGC wraps a benchmark function so that the GC costs of the final run
+// are captured in st. The testing package forces a GC before each run so only
+// the automatic cycles are counted.
+func measureGC(fun func(b *testing.B), st *gcStats) func(b *testing.B) {
+	return func(b *testing.B) {
+		samples := []metrics.Sample{
+			{Name: "/gc/cycles/automatic:gc-cycles"},
+			{Name: "/gc/heap/allocs:bytes"},
+			{Name: "/gc/heap/frees:bytes"},
+		}
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		metrics.Read(samples)
+		startPause := ms.PauseTotalNs
+		startCycles := samples[0].Value.Uint64()
+		startHeap := int64(samples[1].Value.Uint64() - samples[2].Value.Uint64())
+
+		fun(b)
+
+		b.StopTimer()
+		runtime.ReadMemStats(&ms)
+		metrics.Read(samples)
+		st.Cycles = float64(samples[0].Value.Uint64()-startCycles) / float64(b.N)
+		st.Pause = time.Duration(ms.PauseTotalNs - startPause)
+		heap := int64(samples[1].Value.Uint64() - samples[2].Value.Uint64())
+		if st.Growth = heap - startHeap; st.Growth < 0 {
+			st.Growth = 0
+		}
+	}
+}
+
+// fixGC sets GOGC and GOMEMLIMIT to the values given on the command line and
+// returns a function that restores the previous settings.
+func fixGC() func() {
+	percent := debug.SetGCPercent(gcPercent)
+	limit := debug.SetMemoryLimit(int64(gcMemLimit) * 1024 * 1024)
+	return func() {
+		debug.SetGCPercent(percent)
+		debug.SetMemoryLimit(limit)
+	}
+}
+
+func (st *gcStats) String() string {
+	return fmt.Sprintf("%12.4g GCs/op %12s pause %12s heap growth", st.Cycles, st.Pause, memSize(st.Growth))
+}
@@ -5,16 +5,37 @@ package main
 import (
 	"errors"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/tidwall/gjson"
 )
 
 var gjsonPkg = pkg{
-	name: "gjson",
+	name:   "gjson",
+	module: "github.com/tidwall/gjson",
 	calls: map[string]*call{
-		"parse":    {name: "ParseBytes", fun: gjsonParse},
-		"validate": {name: "Validate", fun: gjsonValid},
+		"parse":          {name: "ParseBytes", fun: gjsonParse},
+		"parse-error":    {name: "ValidBytes", fun: gjsonParseError},
+		"numbers":        {name: "ParseBytes", fun: gjsonParseNumbers},
+		"validate":       {name: "Validate", fun: gjsonValid},
+		"decode":         {name: "ForEach", fun: gjsonDecode},
+		"extract-fields": {name: "GetManyBytes", fun: gjsonExtractFields},
+		"extract-log":    {name: "GetManyBytes", fun: gjsonExtractLog},
+	},
+	parse: func(data []byte) (interface{}, error) {
+		// gjson does not check the JSON when parsing so it is validated
+		// first as would be done with untrusted data.
+		if !gjson.ValidBytes(data) {
+			return nil, errors.New("not valid")
+		}
+		return gjson.ParseBytes(data).Value(), nil
+	},
+	validate: func(data []byte) error {
+		if !gjson.ValidBytes(data) {
+			return errors.New("not valid")
+		}
+		return nil
 	},
 }
 
@@ -26,6 +47,67 @@ func gjsonParse(b *testing.B) {
 	}
 }
 
+// gjsonParseError uses ValidBytes since gjson does not detect errors when
+// parsing.
+func gjsonParseError(b *testing.B) {
+	sample, _ := errorSample()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if gjson.ValidBytes(sample) {
+			benchErr = errors.New("error not detected")
+			b.Fail()
+		}
+	}
+}
+
+func gjsonParseNumbers(b *testing.B) {
+	sample := numberSample()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = gjson.ParseBytes(sample).Value()
+	}
+}
+
+func gjsonDecode(b *testing.B) {
+	sample, _ := ioutil.ReadFile(filename)
+	expect := tokenExpect(sample)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if benchErr = tokenCheck(gjsonTokens(gjson.ParseBytes(sample)), expect); benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+// gjsonTokens walks the result with ForEach reading each value and returns
+// the number of tokens encountered.
+func gjsonTokens(r gjson.Result) (cnt int) {
+	switch {
+	case r.IsObject():
+		cnt += 2
+		r.ForEach(func(_, v gjson.Result) bool {
+			cnt += 1 + gjsonTokens(v)
+			return true
+		})
+	case r.IsArray():
+		cnt += 2
+		r.ForEach(func(_, v gjson.Result) bool {
+			cnt += gjsonTokens(v)
+			return true
+		})
+	case r.Type == gjson.String:
+		cnt++
+		_ = r.String()
+	case r.Type == gjson.Number:
+		cnt++
+		_ = r.Float()
+	default:
+		cnt++
+	}
+	return
+}
+
 func gjsonValid(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	b.ResetTimer()
@@ -37,3 +119,26 @@ func gjsonValid(b *testing.B) {
 		}
 	}
 }
+
+func gjsonExtractFields(b *testing.B) {
+	benchExtractFields(b, gjsonExtractor(patientPaths))
+}
+
+func gjsonExtractLog(b *testing.B) {
+	benchExtractLog(b, gjsonExtractor(logPaths))
+}
+
+// gjsonExtractor gets all the values in one call with GetManyBytes. Note
+// gjson does not validate so invalid JSON is not detected.
+func gjsonExtractor(paths [][]interface{}) extractor {
+	gpaths := make([]string, len(paths))
+	for i, path := range paths {
+		gpaths[i] = strings.Join(pathKeys(path), ".")
+	}
+	return func(data []byte, got []string) error {
+		for i, r := range gjson.GetManyBytes(data, gpaths...) {
+			got[i] = r.String()
+		}
+		return nil
+	}
+}
@@ -1,12 +1,21 @@
 module github.com/ohler55/go-json-benchmarks
 
-go 1.16
+go 1.19
 
 require (
 	github.com/json-iterator/go v1.1.11
 	github.com/minio/simdjson-go v0.2.2
-	github.com/mmcloughlin/avo v0.0.0-20201105074841-5d2f697d268f // indirect
 	github.com/ohler55/ojg v1.11.1
 	github.com/tidwall/gjson v1.8.0
 	github.com/valyala/fastjson v1.6.3
 )
+
+require (
+	github.com/klauspost/compress v1.11.7 // indirect
+	github.com/klauspost/cpuid/v2 v2.0.6 // indirect
+	github.com/mmcloughlin/avo v0.0.0-20201105074841-5d2f697d268f // indirect
+	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
+	github.com/modern-go/reflect2 v0.0.0-20180701023420-4b7aa43c6742 // indirect
+	github.com/tidwall/match v1.0.3 // indirect
+	github.com/tidwall/pretty v1.1.0 // indirect
+)
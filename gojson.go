@@ -14,17 +14,55 @@ import (
 )
 
 var jsonPkg = pkg{
-	name: "json",
+	name:   "json",
+	module: "encoding/json",
 	calls: map[string]*call{
-		"parse":            {name: "Unmarshal", fun: goParse},
-		"validate":         {name: "Valid", fun: goValidate},
-		"decode":           {name: "Decode", fun: goDecode},
-		"unmarshal-struct": {name: "Unmarshal", fun: goUnmarshalPatient},
-		"marshal":          {name: "Marshal", fun: goMarshal},
-		"marshal-struct":   {name: "Marshal", fun: goMarshalPatient},
-		"file1":            {name: "Decode", fun: goFile1},
-		"small-file":       {name: "Decode", fun: goFileManySmallLoad},
-		"large-file":       {name: "Decode", fun: goFileManyLarge},
+		"parse":             {name: "Unmarshal", fun: goParse},
+		"parse-error":       {name: "Unmarshal", fun: goParseError},
+		"numbers":           {name: "Unmarshal", fun: goParseNumbers},
+		"numbers-exact":     {name: "UseNumber", fun: goParseNumbersExact},
+		"validate":          {name: "Valid", fun: goValidate},
+		"decode":            {name: "Decode", fun: goDecode},
+		"callback":          {name: "Token", fun: goCallback},
+		"unmarshal-struct":  {name: "Unmarshal", fun: goUnmarshalPatient},
+		"unmarshal-strict":  {name: "Decode", fun: goUnmarshalStrict},
+		"unmarshal-unknown": {name: "Decode", fun: goUnmarshalUnknown},
+		"marshal":           {name: "Marshal", fun: goMarshal},
+		"marshal-struct":    {name: "Marshal", fun: goMarshalPatient},
+		"decompose":         {name: "Marshal+Unmarshal", fun: goDecompose},
+		"recompose":         {name: "Marshal+Unmarshal", fun: goRecompose},
+		"build":             {name: "Encoder", fun: goBuild},
+		"modify":            {name: "Unmarshal+Marshal", fun: goModify},
+		"marshal-floats":    {name: "Marshal", fun: goMarshalFloats},
+		"marshal-html":      {name: "Encoder", fun: goMarshalHTML},
+		"marshal-no-html":   {name: "Encoder", fun: goMarshalNoHTML},
+		"extract-fields":    {name: "Unmarshal", fun: goExtractFields},
+		"extract-log":       {name: "Unmarshal", fun: goExtractLog},
+		"file1":             {name: "Decode", fun: goFile1},
+		"small-file":        {name: "Decode", fun: goFileManySmallLoad},
+		"large-file":        {name: "Decode", fun: goFileManyLarge},
+	},
+	parse: func(data []byte) (v interface{}, err error) {
+		err = json.Unmarshal(data, &v)
+		return
+	},
+	validate: func(data []byte) error {
+		if !json.Valid(data) {
+			return errors.New("not valid")
+		}
+		return nil
+	},
+	marshal:       json.Marshal,
+	unmarshal:     json.Unmarshal,
+	marshalEscape: goMarshalEscape,
+	errorOffset: func(data []byte, err error) int {
+		var se *json.SyntaxError
+		if errors.As(err, &se) {
+			// The offset is the number of bytes read when the error was
+			// detected so the byte in error is the one before.
+			return int(se.Offset) - 1
+		}
+		return -1
 	},
 }
 
@@ -39,6 +77,42 @@ func goParse(b *testing.B) {
 	}
 }
 
+func goParseError(b *testing.B) {
+	sample, _ := errorSample()
+	b.ResetTimer()
+	var result interface{}
+	for n := 0; n < b.N; n++ {
+		if err := json.Unmarshal(sample, &result); err == nil {
+			benchErr = errors.New("error not detected")
+			b.Fail()
+		}
+	}
+}
+
+func goParseNumbers(b *testing.B) {
+	sample := numberSample()
+	b.ResetTimer()
+	var result interface{}
+	for n := 0; n < b.N; n++ {
+		if benchErr = json.Unmarshal(sample, &result); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
+
+func goParseNumbersExact(b *testing.B) {
+	sample := numberSample()
+	b.ResetTimer()
+	var result interface{}
+	for n := 0; n < b.N; n++ {
+		dec := json.NewDecoder(bytes.NewReader(sample))
+		dec.UseNumber()
+		if benchErr = dec.Decode(&result); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
+
 func goValidate(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	b.ResetTimer()
@@ -52,9 +126,11 @@ func goValidate(b *testing.B) {
 
 func goDecode(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
+	expect := tokenExpect(sample)
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
 		dec := json.NewDecoder(bytes.NewReader(sample))
+		cnt := 0
 		for {
 			_, err := dec.Token()
 			if err == io.EOF {
@@ -63,7 +139,30 @@ func goDecode(b *testing.B) {
 			if err != nil {
 				benchErr = err
 				b.Fail()
+				return
 			}
+			cnt++
+		}
+		if benchErr = tokenCheck(cnt, expect); benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+func goCallback(b *testing.B) {
+	sample, _ := ioutil.ReadFile(filename)
+	expect := collectExpect(sample)
+	b.ResetTimer()
+	var c collector
+	for n := 0; n < b.N; n++ {
+		c.reset()
+		if benchErr = goCollect(sample, &c); benchErr == nil {
+			benchErr = c.check(expect)
+		}
+		if benchErr != nil {
+			b.Fail()
+			return
 		}
 	}
 }
@@ -79,6 +178,33 @@ func goUnmarshalPatient(b *testing.B) {
 	}
 }
 
+func goUnmarshalStrict(b *testing.B) {
+	sample := loadStrictSample()
+	b.ResetTimer()
+	var patient Patient
+	for n := 0; n < b.N; n++ {
+		dec := json.NewDecoder(bytes.NewReader(sample))
+		dec.DisallowUnknownFields()
+		if benchErr = dec.Decode(&patient); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
+
+func goUnmarshalUnknown(b *testing.B) {
+	sample := loadUnknownSample()
+	b.ResetTimer()
+	var patient Patient
+	for n := 0; n < b.N; n++ {
+		dec := json.NewDecoder(bytes.NewReader(sample))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&patient); err == nil {
+			benchErr = errors.New("unknown fields not rejected")
+			b.Fail()
+		}
+	}
+}
+
 func goMarshal(b *testing.B) {
 	data := loadSample()
 	b.ResetTimer()
@@ -89,6 +215,218 @@ func goMarshal(b *testing.B) {
 	}
 }
 
+// goMarshalEscape returns a function that marshals with an Encoder since
+// HTML escaping can not be turned off with Marshal. There is no option to
+// escape non-ASCII characters.
+func goMarshalEscape(escapeHTML, escapeASCII bool) func(v interface{}) ([]byte, error) {
+	if escapeASCII {
+		return nil
+	}
+	return func(v interface{}) ([]byte, error) {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(escapeHTML)
+		err := enc.Encode(v)
+		return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), err
+	}
+}
+
+func goMarshalHTML(b *testing.B) {
+	benchMarshalEscape(b, goMarshalEscape(true, false), true)
+}
+
+func goMarshalNoHTML(b *testing.B) {
+	benchMarshalEscape(b, goMarshalEscape(false, false), false)
+}
+
+func goMarshalFloats(b *testing.B) {
+	benchMarshalFloats(b, json.Marshal)
+}
+
+// goDecompose marshals and then unmarshals since encoding/json does not
+// convert directly between structs and simple types.
+func goDecompose(b *testing.B) {
+	benchDecompose(b, func(patient *Patient) (v interface{}, err error) {
+		var j []byte
+		if j, err = json.Marshal(patient); err == nil {
+			err = json.Unmarshal(j, &v)
+		}
+		return
+	})
+}
+
+func goRecompose(b *testing.B) {
+	benchRecompose(b, func(v interface{}) (*Patient, error) {
+		var patient Patient
+		j, err := json.Marshal(v)
+		if err == nil {
+			err = json.Unmarshal(j, &patient)
+		}
+		return &patient, err
+	})
+}
+
+// goBuild builds the build document as maps and lists and then encodes it.
+func goBuild(b *testing.B) {
+	benchBuild(b, func() []byte {
+		doc := map[string]interface{}{
+			"_birthDate": map[string]interface{}{
+				"extension": []interface{}{
+					map[string]interface{}{
+						"url":           "http://hl7.org/fhir/StructureDefinition/patient-birthTime",
+						"valueDateTime": "1974-12-25T14:35:45-05:00",
+					},
+				},
+			},
+			"active": true,
+			"address": []interface{}{
+				map[string]interface{}{
+					"city":       "PleasantVille",
+					"district":   "Rainbow",
+					"line":       []interface{}{"534 Erewhon St"},
+					"period":     map[string]interface{}{"start": "1974-12-25"},
+					"postalCode": "3999",
+					"state":      "Vic",
+					"text":       "534 Erewhon St PeasantVille, Rainbow, Vic  3999",
+					"type":       "both",
+					"use":        "home",
+				},
+			},
+			"birthDate": "1974-12-25",
+			"contact": []interface{}{
+				map[string]interface{}{
+					"address": map[string]interface{}{
+						"city":       "PleasantVille",
+						"district":   "Rainbow",
+						"line":       []interface{}{"534 Erewhon St"},
+						"period":     map[string]interface{}{"start": "1974-12-25"},
+						"postalCode": "3999",
+						"state":      "Vic",
+						"type":       "both",
+						"use":        "home",
+					},
+					"gender": "female",
+					"name": map[string]interface{}{
+						"_family": map[string]interface{}{
+							"extension": []interface{}{
+								map[string]interface{}{
+									"url":         "http://hl7.org/fhir/StructureDefinition/humanname-own-prefix",
+									"valueString": "VV",
+								},
+							},
+						},
+						"family": "du Marché",
+						"given":  []interface{}{"Bénédicte"},
+					},
+					"period": map[string]interface{}{"start": "2012"},
+					"relationship": []interface{}{
+						map[string]interface{}{
+							"coding": []interface{}{
+								map[string]interface{}{
+									"code":   "N",
+									"system": "http://terminology.hl7.org/CodeSystem/v2-0131",
+								},
+							},
+						},
+					},
+					"telecom": []interface{}{
+						map[string]interface{}{
+							"system": "phone",
+							"value":  "+33 (237) 998327",
+						},
+					},
+				},
+			},
+			"deceasedBoolean": false,
+			"gender":          "male",
+			"id":              "example",
+			"identifier": []interface{}{
+				map[string]interface{}{
+					"assigner": map[string]interface{}{"display": "Acme Healthcare"},
+					"period":   map[string]interface{}{"start": "2001-05-06"},
+					"system":   "urn:oid:1.2.36.146.595.217.0.1",
+					"type": map[string]interface{}{
+						"coding": []interface{}{
+							map[string]interface{}{
+								"code":   "MR",
+								"system": "http://terminology.hl7.org/CodeSystem/v2-0203",
+							},
+						},
+					},
+					"use":   "usual",
+					"value": "12345",
+				},
+			},
+			"managingOrganization": map[string]interface{}{"reference": "Organization/1"},
+			"name": []interface{}{
+				map[string]interface{}{
+					"family": "Chalmers",
+					"given":  []interface{}{"Peter", "James"},
+					"use":    "official",
+				},
+				map[string]interface{}{
+					"given": []interface{}{"Jim"},
+					"use":   "usual",
+				},
+				map[string]interface{}{
+					"family": "Windsor",
+					"given":  []interface{}{"Peter", "James"},
+					"period": map[string]interface{}{"end": "2002"},
+					"use":    "maiden",
+				},
+			},
+			"resourceType": "Patient",
+			"telecom": []interface{}{
+				map[string]interface{}{"use": "home"},
+				map[string]interface{}{
+					"rank":   1,
+					"system": "phone",
+					"use":    "work",
+					"value":  "(03) 5555 6473",
+				},
+				map[string]interface{}{
+					"rank":   2,
+					"system": "phone",
+					"use":    "mobile",
+					"value":  "(03) 3410 5613",
+				},
+				map[string]interface{}{
+					"period": map[string]interface{}{"end": "2014"},
+					"system": "phone",
+					"use":    "old",
+					"value":  "(03) 5555 8834",
+				},
+			},
+			"text": map[string]interface{}{
+				"div":    buildDiv,
+				"status": "generated",
+			},
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if benchErr = enc.Encode(doc); benchErr != nil {
+			b.Fail()
+		}
+		return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})
+	})
+}
+
+// goModify decodes the sample, modifies the generic data, and encodes it
+// again.
+func goModify(b *testing.B) {
+	benchModify(b, func(data []byte) ([]byte, error) {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		if err := modifyApply(v); err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	})
+}
+
 func goMarshalPatient(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	var patient Patient
@@ -103,6 +441,28 @@ func goMarshalPatient(b *testing.B) {
 	}
 }
 
+func goExtractFields(b *testing.B) {
+	benchExtractFields(b, func(data []byte, got []string) error {
+		var pf patientFields
+		if err := json.Unmarshal(data, &pf); err != nil {
+			return err
+		}
+		pf.values(got)
+		return nil
+	})
+}
+
+func goExtractLog(b *testing.B) {
+	benchExtractLog(b, func(data []byte, got []string) error {
+		var lf logFields
+		if err := json.Unmarshal(data, &lf); err != nil {
+			return err
+		}
+		lf.values(got)
+		return nil
+	})
+}
+
 func goFile1(b *testing.B) {
 	f, err := os.Open(filename)
 	if err != nil {
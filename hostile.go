@@ -0,0 +1,174 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	showHostile    bool
+	hostileCall    string
+	hostileTimeout = 10 * time.Second
+	hostileStack   = 64   // in MB
+	hostileMem     = 1024 // in MB
+)
+
+// hostileInput is an adversarial input that a package handling untrusted
+// data should reject or parse without crashing or stalling.
+type hostileInput struct {
+	name string
+	gen  func() []byte
+}
+
+var hostileInputs = []hostileInput{
+	{name: "100k deep array", gen: func() []byte {
+		return []byte(strings.Repeat("[", 100000) + strings.Repeat("]", 100000))
+	}},
+	{name: "100k deep object", gen: func() []byte {
+		return []byte(strings.Repeat(`{"a":`, 100000) + "1" + strings.Repeat("}", 100000))
+	}},
+	{name: "4MB string of escapes", gen: func() []byte {
+		unit := `\n\"\\\u00e9`
+		return []byte(`"` + strings.Repeat(unit, 4*1024*1024/len(unit)) + `"`)
+	}},
+	{name: "10^6 digit integer", gen: func() []byte {
+		return []byte("1" + strings.Repeat("2", 999999))
+	}},
+	{name: "10^6 digit fraction", gen: func() []byte {
+		return []byte("[0." + strings.Repeat("3", 1000000) + "]")
+	}},
+	{name: "10^6 digit exponent", gen: func() []byte {
+		return []byte("[1e" + strings.Repeat("9", 1000000) + "]")
+	}},
+	{name: "16MB of whitespace", gen: func() []byte {
+		return []byte(strings.Repeat(" \t\r\n", 4*1024*1024) + "[1]")
+	}},
+}
+
+// hostileResult is written by the child process for a hostile input.
+type hostileResult struct {
+	Ns    int64
+	Err   string
+	Panic string
+}
+
+// hostileReport parses each hostile input with each package in a child
+// process with a timeout and limits on stack size and memory so a package
+// that crashes or stalls does not stop the rest of the run.
+func hostileReport(pkgs []*pkg) {
+	var rows [][]string
+	for i, hi := range hostileInputs {
+		first := hi.name
+		for _, p := range pkgs {
+			if p.parse == nil {
+				continue
+			}
+			outcome, elapsed, rss := runHostile(strconv.Itoa(i) + "/" + p.name)
+			rows = append(rows, []string{first, p.name, outcome, elapsed, memSize(rss)})
+			first = ""
+		}
+	}
+	fmt.Println()
+	fmt.Printf("Hostile input robustness (timeout %s, stack limit %dMB, memory limit %dMB)\n",
+		hostileTimeout, hostileStack, hostileMem)
+	printTable([]string{"input", "package", "result", "time", "peak RSS"}, rows)
+}
+
+// runHostile runs a hostile input in a child process and returns a
+// description of the outcome, the time taken, and peak memory.
+func runHostile(id string) (outcome, elapsed string, rss int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), hostileTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, os.Args[0], childArgs("-hostile-child", id)...)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err := cmd.Run()
+	elapsed = time.Since(start).Round(time.Millisecond).String()
+	rss = peakRSS(cmd.ProcessState)
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return "timeout", "--", rss
+	case err == nil:
+	case strings.Contains(stderr.String(), "stack overflow") || strings.Contains(stderr.String(), "stack exceeds"):
+		return "stack overflow", elapsed, rss
+	case isOutOfMemory(cmd.ProcessState, stderr.String()):
+		return "out of memory", elapsed, rss
+	default:
+		return clip("crashed: " + firstLine(stderr.String())), elapsed, rss
+	}
+	var hr hostileResult
+	if err = json.Unmarshal([]byte(lastLine(stdout.String())), &hr); err != nil {
+		return clip("bad child result: " + err.Error()), elapsed, rss
+	}
+	elapsed = time.Duration(hr.Ns).Round(time.Microsecond).String()
+	switch {
+	case 0 < len(hr.Panic):
+		outcome = clip("panic: " + hr.Panic)
+	case 0 < len(hr.Err):
+		outcome = clip("error: " + hr.Err)
+	default:
+		outcome = "parsed"
+	}
+	return
+}
+
+// runHostileChild parses the hostile input identified by input index and
+// package name and writes the result as JSON to stdout. It is called when
+// the -hostile-child option is given.
+func runHostileChild(pkgs []*pkg, id string) {
+	var hr hostileResult
+	parts := strings.SplitN(id, "/", 2)
+	i, err := strconv.Atoi(parts[0])
+	if err != nil || i < 0 || len(hostileInputs) <= i || len(parts) < 2 {
+		hr.Err = fmt.Sprintf("%s not found", id)
+		writeHostileResult(&hr)
+		return
+	}
+	var p *pkg
+	for _, pp := range pkgs {
+		if pp.name == parts[1] {
+			p = pp
+		}
+	}
+	if p == nil || p.parse == nil {
+		hr.Err = fmt.Sprintf("%s not found", id)
+		writeHostileResult(&hr)
+		return
+	}
+	data := hostileInputs[i].gen()
+	debug.SetMaxStack(hostileStack * 1024 * 1024)
+	limitMemory(hostileMem)
+
+	start := time.Now()
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				hr.Panic = fmt.Sprintf("%v", r)
+			}
+		}()
+		if _, err := p.parse(data); err != nil {
+			hr.Err = err.Error()
+		}
+	}()
+	hr.Ns = int64(time.Since(start))
+	writeHostileResult(&hr)
+}
+
+func writeHostileResult(hr *hostileResult) {
+	j, _ := json.Marshal(hr)
+	fmt.Println()
+	fmt.Println(string(j))
+}
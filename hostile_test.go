@@ -0,0 +1,26 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHostileInputs(t *testing.T) {
+	names := map[string]bool{}
+	for _, hi := range hostileInputs {
+		if names[hi.name] {
+			t.Errorf("duplicate hostile input name %s", hi.name)
+		}
+		names[hi.name] = true
+		data := hi.gen()
+		if len(data) < 100000 {
+			t.Errorf("%s is only %d bytes", hi.name, len(data))
+		}
+		// Only the depth of the nested inputs makes them invalid for json.
+		if hi.name != "100k deep array" && hi.name != "100k deep object" && !json.Valid(data) {
+			t.Errorf("%s is not valid JSON", hi.name)
+		}
+	}
+}
@@ -8,26 +8,65 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 
 	jsoniter "github.com/json-iterator/go"
 )
 
 var jsoniterPkg = pkg{
-	name: "jsoniter",
+	name:   "jsoniter",
+	module: "github.com/json-iterator/go",
+	// There is no modify call since a jsoniter Any is read-only.
 	calls: map[string]*call{
-		"parse":            {name: "Unmarshal", fun: jsoniterUnmarshal},
-		"validate":         {name: "Valid", fun: jsoniterValid},
-		"decode":           {name: "Decode", fun: jsoniterDecode},
-		"unmarshal-struct": {name: "Unmarshal", fun: jsoniterUnmarshalPatient},
-		"marshal":          {name: "Marshal", fun: jsoniterMarshal},
-		"marshal-struct":   {name: "Marshal", fun: jsoniterMarshalPatient},
-		"file1":            {name: "Decode", fun: jsoniterFile1},
-		"small-file":       {name: "Decode", fun: jsoniterFileManySmall},
-		"large-file":       {name: "Decode", fun: jsoniterFileManyLarge},
+		"parse":             {name: "Unmarshal", fun: jsoniterUnmarshal},
+		"parse-error":       {name: "Unmarshal", fun: jsoniterParseError},
+		"numbers":           {name: "Unmarshal", fun: jsoniterParseNumbers},
+		"numbers-exact":     {name: "UseNumber", fun: jsoniterParseNumbersExact},
+		"validate":          {name: "Valid", fun: jsoniterValid},
+		"decode":            {name: "Iterator", fun: jsoniterDecode},
+		"callback":          {name: "Iterator", fun: jsoniterCallback},
+		"unmarshal-struct":  {name: "Unmarshal", fun: jsoniterUnmarshalPatient},
+		"unmarshal-strict":  {name: "Unmarshal", fun: jsoniterUnmarshalStrict},
+		"unmarshal-unknown": {name: "Unmarshal", fun: jsoniterUnmarshalUnknown},
+		"marshal":           {name: "Marshal", fun: jsoniterMarshal},
+		"marshal-struct":    {name: "Marshal", fun: jsoniterMarshalPatient},
+		"decompose":         {name: "Marshal+Unmarshal", fun: jsoniterDecompose},
+		"recompose":         {name: "Marshal+Unmarshal", fun: jsoniterRecompose},
+		"build":             {name: "Stream", fun: jsoniterBuild},
+		"marshal-floats":    {name: "Marshal", fun: jsoniterMarshalFloats},
+		"marshal-html":      {name: "Marshal", fun: jsoniterMarshalHTML},
+		"marshal-no-html":   {name: "Marshal", fun: jsoniterMarshalNoHTML},
+		"extract-fields":    {name: "Get", fun: jsoniterExtractFields},
+		"extract-log":       {name: "Get", fun: jsoniterExtractLog},
+		"file1":             {name: "Decode", fun: jsoniterFile1},
+		"small-file":        {name: "Decode", fun: jsoniterFileManySmall},
+		"large-file":        {name: "Decode", fun: jsoniterFileManyLarge},
 	},
+	parse: func(data []byte) (v interface{}, err error) {
+		err = jsoniter.Unmarshal(data, &v)
+		return
+	},
+	validate: func(data []byte) error {
+		if !jsoniter.Valid(data) {
+			return errors.New("not valid")
+		}
+		return nil
+	},
+	marshal:       jsoniter.Marshal,
+	unmarshal:     jsoniter.Unmarshal,
+	marshalEscape: jsoniterMarshalEscape,
+	errorOffset:   jsoniterErrorOffset,
 }
 
+// jsoniterStrict is the default configuration with unknown fields disallowed.
+var jsoniterStrict = jsoniter.Config{EscapeHTML: true, DisallowUnknownFields: true}.Froze()
+
+// jsoniterNumber is the default configuration with numbers decoded as
+// json.Number.
+var jsoniterNumber = jsoniter.Config{EscapeHTML: true, UseNumber: true}.Froze()
+
 func jsoniterUnmarshal(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	b.ResetTimer()
@@ -40,6 +79,87 @@ func jsoniterUnmarshal(b *testing.B) {
 	}
 }
 
+// jsoniterErrorOffset finds the offset in the error message. The message
+// includes the position relative to the start of a window of up to 10 bytes
+// either side of the error and a larger context of up to 50 bytes either side
+// so the window and context are located in the data to get the offset.
+func jsoniterErrorOffset(data []byte, err error) int {
+	msg := err.Error()
+	i := strings.Index(msg, "error found in #")
+	if i < 0 {
+		return -1
+	}
+	msg = msg[i+len("error found in #"):]
+	var pos int
+	var window string
+	var context string
+	if i = strings.Index(msg, " byte of ...|"); i < 0 {
+		return -1
+	}
+	if pos, err = strconv.Atoi(msg[:i]); err != nil {
+		return -1
+	}
+	msg = msg[i+len(" byte of ...|"):]
+	if i = strings.Index(msg, "|..., bigger context ...|"); i < 0 {
+		return -1
+	}
+	window = msg[:i]
+	context = strings.TrimSuffix(msg[i+len("|..., bigger context ...|"):], "|...")
+	for start := 0; start < len(data); start++ {
+		j := bytes.Index(data[start:], []byte(window))
+		if j < 0 {
+			break
+		}
+		offset := start + j + pos
+		cs := offset - 50
+		if cs < 0 {
+			cs = 0
+		}
+		if bytes.HasPrefix(data[cs:], []byte(context)) {
+			return offset
+		}
+		start += j
+	}
+	return -1
+}
+
+func jsoniterParseError(b *testing.B) {
+	sample, _ := errorSample()
+	b.ResetTimer()
+
+	var result interface{}
+	for n := 0; n < b.N; n++ {
+		if err := jsoniter.Unmarshal(sample, &result); err == nil {
+			benchErr = errors.New("error not detected")
+			b.Fail()
+		}
+	}
+}
+
+func jsoniterParseNumbers(b *testing.B) {
+	sample := numberSample()
+	b.ResetTimer()
+
+	var result interface{}
+	for n := 0; n < b.N; n++ {
+		if benchErr = jsoniter.Unmarshal(sample, &result); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
+
+func jsoniterParseNumbersExact(b *testing.B) {
+	sample := numberSample()
+	b.ResetTimer()
+
+	var result interface{}
+	for n := 0; n < b.N; n++ {
+		if benchErr = jsoniterNumber.Unmarshal(sample, &result); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
+
 func jsoniterValid(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	b.ResetTimer()
@@ -53,18 +173,108 @@ func jsoniterValid(b *testing.B) {
 
 func jsoniterDecode(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
+	expect := tokenExpect(sample)
 	b.ResetTimer()
 
-	var data interface{}
+	iter := jsoniter.NewIterator(jsoniter.ConfigDefault)
 	for n := 0; n < b.N; n++ {
-		dec := jsoniter.NewDecoder(bytes.NewReader(sample))
-		if err := dec.Decode(&data); err != nil {
-			benchErr = err
+		iter.ResetBytes(sample)
+		cnt := jsoniterTokens(iter)
+		if iter.Error != nil {
+			benchErr = iter.Error
+			b.Fail()
+			return
+		}
+		if benchErr = tokenCheck(cnt, expect); benchErr != nil {
 			b.Fail()
+			return
 		}
 	}
 }
 
+// jsoniterTokens walks the next value with the iterator reading each value
+// and returns the number of tokens encountered.
+func jsoniterTokens(iter *jsoniter.Iterator) (cnt int) {
+	switch iter.WhatIsNext() {
+	case jsoniter.ObjectValue:
+		cnt += 2
+		iter.ReadObjectCB(func(it *jsoniter.Iterator, _ string) bool {
+			cnt += 1 + jsoniterTokens(it)
+			return true
+		})
+	case jsoniter.ArrayValue:
+		cnt += 2
+		iter.ReadArrayCB(func(it *jsoniter.Iterator) bool {
+			cnt += jsoniterTokens(it)
+			return true
+		})
+	case jsoniter.StringValue:
+		cnt++
+		_ = iter.ReadString()
+	case jsoniter.NumberValue:
+		cnt++
+		_ = iter.ReadNumber()
+	case jsoniter.BoolValue:
+		cnt++
+		_ = iter.ReadBool()
+	case jsoniter.NilValue:
+		cnt++
+		_ = iter.ReadNil()
+	default:
+		iter.Skip()
+	}
+	return
+}
+
+func jsoniterCallback(b *testing.B) {
+	sample, _ := ioutil.ReadFile(filename)
+	expect := collectExpect(sample)
+	b.ResetTimer()
+
+	iter := jsoniter.NewIterator(jsoniter.ConfigDefault)
+	var c collector
+	for n := 0; n < b.N; n++ {
+		c.reset()
+		iter.ResetBytes(sample)
+		jsoniterCollect(iter, &c)
+		if benchErr = iter.Error; benchErr == nil {
+			benchErr = c.check(expect)
+		}
+		if benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+// jsoniterCollect walks the next value with the iterator and feeds the
+// values to the collector.
+func jsoniterCollect(iter *jsoniter.Iterator, c *collector) {
+	switch iter.WhatIsNext() {
+	case jsoniter.ObjectValue:
+		c.start()
+		iter.ReadObjectCB(func(it *jsoniter.Iterator, key string) bool {
+			c.key(key)
+			jsoniterCollect(it, c)
+			return true
+		})
+		c.end()
+	case jsoniter.ArrayValue:
+		c.start()
+		iter.ReadArrayCB(func(it *jsoniter.Iterator) bool {
+			jsoniterCollect(it, c)
+			return true
+		})
+		c.end()
+	case jsoniter.StringValue:
+		c.str(iter.ReadString())
+	case jsoniter.NumberValue:
+		c.num(iter.ReadFloat64())
+	default:
+		iter.Skip()
+	}
+}
+
 func jsoniterUnmarshalPatient(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	b.ResetTimer()
@@ -77,6 +287,31 @@ func jsoniterUnmarshalPatient(b *testing.B) {
 	}
 }
 
+func jsoniterUnmarshalStrict(b *testing.B) {
+	sample := loadStrictSample()
+	b.ResetTimer()
+
+	var patient Patient
+	for n := 0; n < b.N; n++ {
+		if benchErr = jsoniterStrict.Unmarshal(sample, &patient); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
+
+func jsoniterUnmarshalUnknown(b *testing.B) {
+	sample := loadUnknownSample()
+	b.ResetTimer()
+
+	var patient Patient
+	for n := 0; n < b.N; n++ {
+		if err := jsoniterStrict.Unmarshal(sample, &patient); err == nil {
+			benchErr = errors.New("unknown fields not rejected")
+			b.Fail()
+		}
+	}
+}
+
 func jsoniterMarshal(b *testing.B) {
 	data := loadSample()
 	b.ResetTimer()
@@ -87,6 +322,394 @@ func jsoniterMarshal(b *testing.B) {
 	}
 }
 
+// jsoniterMarshalEscape returns the Marshal function of a configuration with
+// EscapeHTML set. There is no option to escape non-ASCII characters.
+func jsoniterMarshalEscape(escapeHTML, escapeASCII bool) func(v interface{}) ([]byte, error) {
+	if escapeASCII {
+		return nil
+	}
+	// Keys are sorted as they are by encoding/json.
+	return jsoniter.Config{EscapeHTML: escapeHTML, SortMapKeys: true}.Froze().Marshal
+}
+
+func jsoniterMarshalHTML(b *testing.B) {
+	benchMarshalEscape(b, jsoniterMarshalEscape(true, false), true)
+}
+
+func jsoniterMarshalNoHTML(b *testing.B) {
+	benchMarshalEscape(b, jsoniterMarshalEscape(false, false), false)
+}
+
+func jsoniterMarshalFloats(b *testing.B) {
+	benchMarshalFloats(b, jsoniter.Marshal)
+}
+
+// jsoniterDecompose marshals and then unmarshals since jsoniter does not
+// convert directly between structs and simple types.
+func jsoniterDecompose(b *testing.B) {
+	benchDecompose(b, func(patient *Patient) (v interface{}, err error) {
+		var j []byte
+		if j, err = jsoniter.Marshal(patient); err == nil {
+			err = jsoniter.Unmarshal(j, &v)
+		}
+		return
+	})
+}
+
+func jsoniterRecompose(b *testing.B) {
+	benchRecompose(b, func(v interface{}) (*Patient, error) {
+		var patient Patient
+		j, err := jsoniter.Marshal(v)
+		if err == nil {
+			err = jsoniter.Unmarshal(j, &patient)
+		}
+		return &patient, err
+	})
+}
+
+// jsoniterBuild writes the build document directly to a Stream configured to
+// not escape HTML characters.
+func jsoniterBuild(b *testing.B) {
+	stream := jsoniter.NewStream(jsoniter.Config{}.Froze(), nil, 4096)
+	benchBuild(b, func() []byte {
+		stream.SetBuffer(stream.Buffer()[:0])
+		stream.WriteObjectStart()
+
+		stream.WriteObjectField("_birthDate")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("extension")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("url")
+		stream.WriteString("http://hl7.org/fhir/StructureDefinition/patient-birthTime")
+		stream.WriteMore()
+		stream.WriteObjectField("valueDateTime")
+		stream.WriteString("1974-12-25T14:35:45-05:00")
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("active")
+		stream.WriteTrue()
+		stream.WriteMore()
+
+		stream.WriteObjectField("address")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("city")
+		stream.WriteString("PleasantVille")
+		stream.WriteMore()
+		stream.WriteObjectField("district")
+		stream.WriteString("Rainbow")
+		stream.WriteMore()
+		stream.WriteObjectField("line")
+		stream.WriteArrayStart()
+		stream.WriteString("534 Erewhon St")
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("period")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("start")
+		stream.WriteString("1974-12-25")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("postalCode")
+		stream.WriteString("3999")
+		stream.WriteMore()
+		stream.WriteObjectField("state")
+		stream.WriteString("Vic")
+		stream.WriteMore()
+		stream.WriteObjectField("text")
+		stream.WriteString("534 Erewhon St PeasantVille, Rainbow, Vic  3999")
+		stream.WriteMore()
+		stream.WriteObjectField("type")
+		stream.WriteString("both")
+		stream.WriteMore()
+		stream.WriteObjectField("use")
+		stream.WriteString("home")
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("birthDate")
+		stream.WriteString("1974-12-25")
+		stream.WriteMore()
+
+		stream.WriteObjectField("contact")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("address")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("city")
+		stream.WriteString("PleasantVille")
+		stream.WriteMore()
+		stream.WriteObjectField("district")
+		stream.WriteString("Rainbow")
+		stream.WriteMore()
+		stream.WriteObjectField("line")
+		stream.WriteArrayStart()
+		stream.WriteString("534 Erewhon St")
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("period")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("start")
+		stream.WriteString("1974-12-25")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("postalCode")
+		stream.WriteString("3999")
+		stream.WriteMore()
+		stream.WriteObjectField("state")
+		stream.WriteString("Vic")
+		stream.WriteMore()
+		stream.WriteObjectField("type")
+		stream.WriteString("both")
+		stream.WriteMore()
+		stream.WriteObjectField("use")
+		stream.WriteString("home")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("gender")
+		stream.WriteString("female")
+		stream.WriteMore()
+		stream.WriteObjectField("name")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("_family")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("extension")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("url")
+		stream.WriteString("http://hl7.org/fhir/StructureDefinition/humanname-own-prefix")
+		stream.WriteMore()
+		stream.WriteObjectField("valueString")
+		stream.WriteString("VV")
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("family")
+		stream.WriteString("du Marché")
+		stream.WriteMore()
+		stream.WriteObjectField("given")
+		stream.WriteArrayStart()
+		stream.WriteString("Bénédicte")
+		stream.WriteArrayEnd()
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("period")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("start")
+		stream.WriteString("2012")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("relationship")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("coding")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("code")
+		stream.WriteString("N")
+		stream.WriteMore()
+		stream.WriteObjectField("system")
+		stream.WriteString("http://terminology.hl7.org/CodeSystem/v2-0131")
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("telecom")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("system")
+		stream.WriteString("phone")
+		stream.WriteMore()
+		stream.WriteObjectField("value")
+		stream.WriteString("+33 (237) 998327")
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("deceasedBoolean")
+		stream.WriteFalse()
+		stream.WriteMore()
+		stream.WriteObjectField("gender")
+		stream.WriteString("male")
+		stream.WriteMore()
+		stream.WriteObjectField("id")
+		stream.WriteString("example")
+		stream.WriteMore()
+
+		stream.WriteObjectField("identifier")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("assigner")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("display")
+		stream.WriteString("Acme Healthcare")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("period")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("start")
+		stream.WriteString("2001-05-06")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("system")
+		stream.WriteString("urn:oid:1.2.36.146.595.217.0.1")
+		stream.WriteMore()
+		stream.WriteObjectField("type")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("coding")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("code")
+		stream.WriteString("MR")
+		stream.WriteMore()
+		stream.WriteObjectField("system")
+		stream.WriteString("http://terminology.hl7.org/CodeSystem/v2-0203")
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("use")
+		stream.WriteString("usual")
+		stream.WriteMore()
+		stream.WriteObjectField("value")
+		stream.WriteString("12345")
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("managingOrganization")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("reference")
+		stream.WriteString("Organization/1")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+
+		stream.WriteObjectField("name")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("family")
+		stream.WriteString("Chalmers")
+		stream.WriteMore()
+		stream.WriteObjectField("given")
+		stream.WriteArrayStart()
+		stream.WriteString("Peter")
+		stream.WriteMore()
+		stream.WriteString("James")
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("use")
+		stream.WriteString("official")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("given")
+		stream.WriteArrayStart()
+		stream.WriteString("Jim")
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("use")
+		stream.WriteString("usual")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("family")
+		stream.WriteString("Windsor")
+		stream.WriteMore()
+		stream.WriteObjectField("given")
+		stream.WriteArrayStart()
+		stream.WriteString("Peter")
+		stream.WriteMore()
+		stream.WriteString("James")
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("period")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("end")
+		stream.WriteString("2002")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("use")
+		stream.WriteString("maiden")
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("resourceType")
+		stream.WriteString("Patient")
+		stream.WriteMore()
+
+		stream.WriteObjectField("telecom")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("use")
+		stream.WriteString("home")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("rank")
+		stream.WriteInt(1)
+		stream.WriteMore()
+		stream.WriteObjectField("system")
+		stream.WriteString("phone")
+		stream.WriteMore()
+		stream.WriteObjectField("use")
+		stream.WriteString("work")
+		stream.WriteMore()
+		stream.WriteObjectField("value")
+		stream.WriteString("(03) 5555 6473")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("rank")
+		stream.WriteInt(2)
+		stream.WriteMore()
+		stream.WriteObjectField("system")
+		stream.WriteString("phone")
+		stream.WriteMore()
+		stream.WriteObjectField("use")
+		stream.WriteString("mobile")
+		stream.WriteMore()
+		stream.WriteObjectField("value")
+		stream.WriteString("(03) 3410 5613")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectStart()
+		stream.WriteObjectField("period")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("end")
+		stream.WriteString("2014")
+		stream.WriteObjectEnd()
+		stream.WriteMore()
+		stream.WriteObjectField("system")
+		stream.WriteString("phone")
+		stream.WriteMore()
+		stream.WriteObjectField("use")
+		stream.WriteString("old")
+		stream.WriteMore()
+		stream.WriteObjectField("value")
+		stream.WriteString("(03) 5555 8834")
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+		stream.WriteMore()
+
+		stream.WriteObjectField("text")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("div")
+		stream.WriteString(buildDiv)
+		stream.WriteMore()
+		stream.WriteObjectField("status")
+		stream.WriteString("generated")
+		stream.WriteObjectEnd()
+
+		stream.WriteObjectEnd()
+		return stream.Buffer()
+	})
+}
+
 func jsoniterMarshalPatient(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	var patient Patient
@@ -101,6 +724,28 @@ func jsoniterMarshalPatient(b *testing.B) {
 	}
 }
 
+func jsoniterExtractFields(b *testing.B) {
+	benchExtractFields(b, jsoniterExtractor(patientPaths))
+}
+
+func jsoniterExtractLog(b *testing.B) {
+	benchExtractLog(b, jsoniterExtractor(logPaths))
+}
+
+// jsoniterExtractor uses the lazy jsoniter.Get for each value.
+func jsoniterExtractor(paths [][]interface{}) extractor {
+	return func(data []byte, got []string) error {
+		for i, path := range paths {
+			v := jsoniter.Get(data, path...)
+			if err := v.LastError(); err != nil {
+				return err
+			}
+			got[i] = v.ToString()
+		}
+		return nil
+	}
+}
+
 func jsoniterFile1(b *testing.B) {
 	f, err := os.Open(filename)
 	if err != nil {
@@ -3,21 +3,16 @@
 package main
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"math"
 	"os"
-	"os/exec"
 	"sort"
-	"strconv"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/ohler55/ojg/alt"
 	"github.com/ohler55/ojg/jp"
 	"github.com/ohler55/ojg/oj"
 )
@@ -36,15 +31,60 @@ var (
 
 	largeLogFile = "data/log-large.json"
 	largeSize    = 5000
+
+	memLimit      int // in MB
+	childCall     string
+	genBench      string
+	showFuzz      bool
+	updateFuzz    bool
+	showRoundTrip bool
+
+	showGC     bool
+	gcPercent  = 100
+	gcMemLimit = 64 // in MB
 )
 
-type specs struct {
-	os        string
-	model     string
-	processor string
-	cores     string
-	speed     string
-	memory    string
+// packages are the packages compared in each suite.
+var packages = []*pkg{
+	&jsonPkg,
+	&ojPkg,
+	&fastjsonPkg,
+	&jsoniterPkg,
+	&simdjsonPkg,
+	&gjsonPkg,
+}
+
+// suites are run in order by main and also exposed as go test benchmarks in
+// bench_test.go.
+var suites = []*suite{
+	{fun: "parse", title: "Parse string/[]byte to simple go types ([]interface{}, int64, string, etc)", ref: "json"},
+	{fun: "numbers", title: "Parse a number heavy string/[]byte to simple go types", ref: "json"},
+	{fun: "numbers-exact", title: "Parse a number heavy string/[]byte without losing precision", ref: "json", base: "numbers"},
+	{fun: "parse-error", title: "Parse string/[]byte with an error in the middle", ref: "json", base: "parse"},
+	{fun: "sen-parse", title: "Parse SEN (unquoted keys, comments, no commas) to simple go types", ref: "oj", base: "parse"},
+	{fun: "validate", title: "Validate string/[]byte", ref: "json"},
+	{fun: "decode", title: "Iterate tokens in a string/[]byte", ref: "json"},
+	{fun: "callback", title: "Tokenize with a handler that counts keys, sums numbers, and collects strings", ref: "json"},
+	{fun: "unmarshal-struct", title: "Unmarshal string/[]byte to a struct", ref: "json"},
+	{fun: "unmarshal-strict", title: "Unmarshal string/[]byte to a struct disallowing unknown fields", ref: "json"},
+	{fun: "unmarshal-unknown", title: "Reject unknown fields when unmarshalling to a struct", ref: "json"},
+	{fun: "marshal", title: "Marshal simple types to string/[]byte", ref: "json"},
+	{fun: "marshal-struct", title: "Marshal a struct to string/[]byte", ref: "json"},
+	{fun: "decompose", title: "Convert a Patient struct to simple types", ref: "json"},
+	{fun: "recompose", title: "Convert simple types to a Patient struct", ref: "json"},
+	{fun: "build", title: "Build the sample field by field and write it to string/[]byte", ref: "json"},
+	{fun: "modify", title: "Parse string/[]byte, set and delete values by path, and write it", ref: "json"},
+	{fun: "sen-write", title: "Write simple types as SEN to string/[]byte", ref: "oj", base: "marshal"},
+	{fun: "marshal-floats", title: "Marshal a float heavy sample to string/[]byte", ref: "json"},
+	{fun: "marshal-html", title: "Marshal a string heavy sample escaping <, >, and &", ref: "json"},
+	{fun: "marshal-no-html", title: "Marshal a string heavy sample without escaping <, >, and &", ref: "json", base: "marshal-html"},
+	{fun: "parse", title: "Parse string/[]byte to simple go types with a fixed GOGC and GOMEMLIMIT", ref: "json", fixedGC: true},
+	{fun: "unmarshal-struct", title: "Unmarshal string/[]byte to a struct with a fixed GOGC and GOMEMLIMIT", ref: "json", fixedGC: true},
+	{fun: "extract-fields", title: "Extract a few fields from a string/[]byte", ref: "json", base: "parse"},
+	{fun: "file1", title: "Read from single JSON file", ref: "json"},
+	{fun: "small-file", title: "Read multiple JSON in a small log file (100MB)", ref: "json"},
+	{fun: "large-file", title: "Read multiple JSON in a semi large log file (5GB)", ref: "json", isolate: true, benchtime: "1x"},
+	{fun: "extract-log", title: "Extract a few fields from each entry in a semi large log file (5GB)", ref: "json", base: "large-file", benchtime: "1x"},
 }
 
 type call struct {
@@ -55,23 +95,57 @@ type call struct {
 	bytes  int64 // base adjusted
 	allocs int64 // base adjusted
 	err    error
+	gc     gcStats
+
+	// Only set when run in a child process.
+	peakRSS  int64
+	peakHeap int64
+	gcs      int64
 }
 
 type pkg struct {
-	name  string
-	calls map[string]*call
+	name    string
+	module  string // module path or standard library package path
+	version string // module version from the build info
+	calls   map[string]*call
+
+	// parse, validate, marshal, and unmarshal are used for behavior checks
+	// and not benchmarks. Parse returns simple go types. Marshal and
+	// unmarshal are nil if the package does not support structs.
+	parse     func(data []byte) (interface{}, error)
+	validate  func(data []byte) error
+	marshal   func(v interface{}) ([]byte, error)
+	unmarshal func(data []byte, v interface{}) error
+
+	// marshalEscape returns a marshal function that escapes the HTML
+	// characters <, >, and & or not and escapes non-ASCII characters or not.
+	// It is nil if the package does not marshal or does not have an option
+	// for escaping HTML. The returned function is nil if the package does
+	// not have an option for the combination.
+	marshalEscape func(escapeHTML, escapeASCII bool) func(v interface{}) ([]byte, error)
+
+	// errorOffset returns the byte offset of the position reported in an
+	// error returned by parse or -1 if the error does not include a
+	// position. It is nil if the package errors never include a position.
+	errorOffset func(data []byte, err error) int
 }
 
 type result struct {
-	pkg  string
-	call *call
-	ref  bool
+	pkg     string
+	version string // module version of the package
+	call    *call
+	ref     bool
 }
 
 type suite struct {
-	title string
-	fun   string // key into the pkg calls
-	ref   string // reference package for the suite
+	title   string
+	fun     string // key into the pkg calls
+	ref     string // reference package for the suite
+	base    string // optional earlier suite each package is compared against
+	isolate bool   // run each call in a child process
+	fixedGC bool   // run with GOGC and GOMEMLIMIT fixed
+
+	benchtime string // optional duration or Nx that overrides the global benchtime
 }
 
 type noWriter int
@@ -80,34 +154,88 @@ func (w noWriter) Write(b []byte) (int, error) {
 	return len(b), nil
 }
 
+//go:generate go run . -gen-bench bench_test.go
+
 func main() {
 	testing.Init()
+	flag.IntVar(&memLimit, "mem-limit", 0, "memory limit in MB for each package in suites run in a child process")
+	flag.StringVar(&childCall, "child", "", "run a single suite/package call and write the result (used internally)")
+	flag.StringVar(&genBench, "gen-bench", "", "write the go test benchmarks for the suites to a file and exit")
+	flag.BoolVar(&showHostile, "hostile", false, "display how each package handles hostile inputs and exit")
+	flag.StringVar(&hostileCall, "hostile-child", "", "parse a single input/package hostile input and write the result (used internally)")
+	flag.DurationVar(&hostileTimeout, "hostile-timeout", hostileTimeout, "time limit for each package to parse each hostile input")
+	flag.IntVar(&hostileStack, "hostile-stack", hostileStack, "stack limit in MB when parsing hostile inputs")
+	flag.IntVar(&hostileMem, "hostile-mem", hostileMem, "memory limit in MB when parsing hostile inputs")
+	flag.BoolVar(&showFuzz, "fuzz-report", false, "display the known divergences in the fuzz corpus and exit")
+	flag.BoolVar(&updateFuzz, "fuzz-update", false, "record the current divergence of each fuzz seed as the expected outcome and exit")
+	flag.BoolVar(&showRoundTrip, "round-trip", false, "display how documents change after a parse, marshal, and parse cycle and exit")
+	flag.BoolVar(&showGC, "gc", false, "show GC cycles, pause time, and heap growth for each call")
+	flag.IntVar(&gcPercent, "gogc", gcPercent, "GOGC used by the fixed GC suites")
+	flag.IntVar(&gcMemLimit, "gomemlimit", gcMemLimit, "GOMEMLIMIT in MB used by the fixed GC suites")
+	flag.StringVar(&benchTime, "benchtime", "", "run each call for a duration such as 2s or a fixed number of iterations such as 100x")
+	flag.DurationVar(&budget, "budget", 0, "wall-clock budget for each suite, shortens the benchtime if needed")
+	flag.BoolVar(&shuffle, "shuffle", false, "randomize the package order in each suite")
+	flag.Int64Var(&seed, "seed", 0, "seed for -shuffle, a random seed is used if not set")
+	flag.BoolVar(&warmUp, "warmup", false, "run a discarded warm-up iteration before each call")
+	flag.BoolVar(&gcBetween, "gc-between", false, "force a GC before each call")
+	flag.StringVar(&cpuList, "cpus", "", "pin the process to a list of CPUs such as 0,2-3 (linux only)")
+	flag.StringVar(&cpuProfileDir, "cpuprofile-dir", "", "directory to write a CPU profile to for each suite and package")
+	flag.StringVar(&memProfileDir, "memprofile-dir", "", "directory to write a memory profile to for each suite and package")
+	flag.IntVar(&profileTop, "profile-top", 0, "number of top functions from each profile to display under the suite results")
 	flag.Parse()
 	if 0 < len(flag.Args()) {
 		filename = flag.Args()[0]
 	}
 
-	pkgs := []*pkg{
-		&jsonPkg,
-		&ojPkg,
-		&fastjsonPkg,
-		&jsoniterPkg,
-		&simdjsonPkg,
-		&gjsonPkg,
-	}
-	for _, s := range []*suite{
-		{fun: "parse", title: "Parse string/[]byte to simple go types ([]interface{}, int64, string, etc)", ref: "json"},
-		{fun: "validate", title: "Validate string/[]byte", ref: "json"},
-		{fun: "decode", title: "Iterate tokens in a string/[]byte", ref: "json"},
-		{fun: "unmarshal-struct", title: "Unmarshal string/[]byte to a struct", ref: "json"},
-		{fun: "marshal", title: "Marshal simple types to string/[]byte", ref: "json"},
-		{fun: "marshal-struct", title: "Marshal a struct to string/[]byte", ref: "json"},
-		{fun: "file1", title: "Read from single JSON file", ref: "json"},
-		{fun: "small-file", title: "Read multiple JSON in a small log file (100MB)", ref: "json"},
-		{fun: "large-file", title: "Read multiple JSON in a semi large log file (5GB)", ref: "json"},
-	} {
-		s.exec(pkgs)
+	if 0 < len(genBench) {
+		writeBenchTests(genBench)
+		return
+	}
+	if 0 < len(cpuList) {
+		if err := pin(cpuList); err != nil {
+			log.Fatalf("Failed to pin to CPUs %s. %s\n", cpuList, err)
+		}
+	}
+	if 0 < len(childCall) {
+		runChild(packages, childCall)
+		return
+	}
+	if 0 < len(hostileCall) {
+		runHostileChild(packages, hostileCall)
+		return
+	}
+	if showFuzz {
+		fuzzReport(packages)
+		return
+	}
+	if updateFuzz {
+		fuzzUpdate(packages)
+		return
+	}
+	if showRoundTrip {
+		roundTripReport(packages)
+		return
+	}
+	if showHostile {
+		hostileReport(packages)
+		return
+	}
+	useBenchtime(benchTime) // validates the benchtime
+	printPackages(packages)
+	if shuffle {
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		fmt.Printf("\nPackage order shuffled with -seed %d\n", seed)
 	}
+	for _, s := range suites {
+		s.exec(packages)
+	}
+	tagCoverage(packages)
+	numberPrecision(packages)
+	errorReport(packages)
+	escapeReport(packages)
+	floatReport(packages)
 	// TBD read multiple json, indented small, maybe a few patients in one file
 	// TBD validate io.Reader
 
@@ -116,73 +244,196 @@ func main() {
 	fmt.Println(" parsing performance. The lighter colored bar is the reference, the go json")
 	fmt.Println(" package.")
 	fmt.Println()
-	if s := getSpecs(); s != nil {
-		fmt.Println("Tests run on:")
-		if 0 < len(s.model) {
-			fmt.Printf(" Machine:         %s\n", s.model)
-		}
-		fmt.Printf(" OS:              %s\n", s.os)
-		fmt.Printf(" Processor:       %s\n", s.processor)
-		fmt.Printf(" Cores:           %s\n", s.cores)
-		fmt.Printf(" Processor Speed: %s\n", s.speed)
-		fmt.Printf(" Memory:          %s\n", s.memory)
-	}
+	getSpecs().display()
 	fmt.Println()
 }
 
 func (s *suite) exec(pkgs []*pkg) {
 	fmt.Println()
 	fmt.Println(s.title)
+	start := time.Now()
+	var calls int
+	for _, p := range pkgs {
+		if p.calls[s.fun] != nil {
+			calls++
+		}
+	}
+	if bt := s.callBenchtime(calls); 0 < len(bt) {
+		fmt.Printf(" (benchtime %s)\n", bt)
+		defer useBenchtime(bt)()
+	}
+	if s.fixedGC {
+		fmt.Printf(" (GOGC=%d GOMEMLIMIT=%dMB)\n", gcPercent, gcMemLimit)
+		defer fixGC()()
+	}
 	var results []*result
 	var ref *call
-	for _, p := range pkgs {
+	for _, p := range order(pkgs) {
 		benchErr = nil
 		c := p.calls[s.fun]
-		r := result{pkg: p.name, call: c, ref: s.ref == p.name}
+		r := result{pkg: p.name, version: p.version, call: c, ref: s.ref == p.name}
 		results = append(results, &r)
 		if c == nil {
 			r.call = &call{ns: math.MaxInt64, err: fmt.Errorf("not supported")}
 			fmt.Printf(" %8s >>> not supported <<<\n", p.name)
 			continue
 		}
+		if s.fixedGC {
+			// Use a copy so the results of the suite without a fixed GC are
+			// not replaced.
+			fc := *c
+			c = &fc
+			r.call = c
+		}
 		if r.ref {
 			ref = c
 		}
-		c.res = testing.Benchmark(c.fun)
-		if benchErr != nil {
-			c.err = benchErr
-			c.ns = math.MaxInt64
-			fmt.Printf(" %8s.%-11s >>> %s <<<\n", p.name, c.name, benchErr)
-			continue
+		if s.isolate {
+			r.version = s.runIsolated(p, c)
+			if c.err != nil {
+				c.ns = math.MaxInt64
+				fmt.Printf(" %8s.%-11s >>> %s <<<\n", p.name, c.name, c.err)
+				continue
+			}
+		} else {
+			prepare(c)
+			profileRun(profileName(s.id(), p.name), func() {
+				c.res = testing.Benchmark(measureGC(c.fun, &c.gc))
+			})
+			if benchErr != nil {
+				c.err = benchErr
+				c.ns = math.MaxInt64
+				fmt.Printf(" %8s.%-11s >>> %s <<<\n", p.name, c.name, benchErr)
+				continue
+			}
+			c.ns = c.res.NsPerOp()
+			c.bytes = c.res.AllocedBytesPerOp()
+			c.allocs = c.res.AllocsPerOp()
+		}
+		fmt.Printf(" %8s.%-11s %12d ns/op %12d B/op %12d allocs/op %10d iterations  %s\n",
+			p.name, c.name, c.ns, c.bytes, c.allocs, c.res.N, r.version)
+		if s.isolate {
+			fmt.Printf(" %8s %-11s %12s peak RSS %9s peak heap %12d GCs\n",
+				"", "", memSize(c.peakRSS), memSize(c.peakHeap), c.gcs)
+		}
+		if showGC || s.fixedGC {
+			fmt.Printf(" %8s %-11s %s\n", "", "", &c.gc)
 		}
-		c.ns = c.res.NsPerOp()
-		c.bytes = c.res.AllocedBytesPerOp()
-		c.allocs = c.res.AllocsPerOp()
-		fmt.Printf(" %8s.%-11s %12d ns/op %12d B/op %12d allocs/op\n",
-			p.name, c.name, c.ns, c.bytes, c.allocs)
 	}
 	fmt.Println()
+	s.chart(results, ref)
+	if 0 < len(s.base) {
+		s.compareBase(pkgs)
+	}
+	if 0 < profileTop {
+		s.printProfiles(pkgs)
+	}
+	fmt.Printf("\n Suite run time: %s\n", time.Since(start).Round(time.Millisecond))
+}
+
+// id returns an identifier for the suite that is unique even when more than
+// one suite uses the same function.
+func (s *suite) id() string {
+	if s.fixedGC {
+		return s.fun + "-fixed-gc"
+	}
+	return s.fun
+}
+
+// chart displays a bar for each result sorted from fastest to slowest and
+// scaled relative to the reference package.
+func (s *suite) chart(results []*result, ref *call) {
 	scale := 7 // TBD adjust to fit screen better?
-	sort.Slice(results, func(i, j int) bool { return results[i].call.ns < results[j].call.ns })
+	sortResults(results)
 	for _, r := range results {
 		c := r.call
-		x := 1.0
-		var bar string
-		if r.pkg == s.ref {
-			bar = strings.Repeat(darkBlock, scale)
-		} else {
-			if c.err == nil {
-				x = float64(ref.ns) / float64(c.ns)
-				size := x * float64(scale)
-				bar = strings.Repeat(string([]rune(blocks)[8:]), int(size))
-				frac := int(size*8.0) - (int(size) * 8)
-				bar += string([]rune(blocks)[frac : frac+1])
+		switch {
+		case c.err != nil:
+			fmt.Printf(" %8s >>> %s <<<\n", r.pkg, c.err)
+		case r.ref:
+			fmt.Printf(" %8s %s %3.2f\n", r.pkg, strings.Repeat(darkBlock, scale), 1.0)
+		case ref == nil || ref.err != nil:
+			fmt.Printf(" %8s >>> no %s result to compare to <<<\n", r.pkg, s.ref)
+		default:
+			x := float64(ref.ns) / float64(c.ns)
+			fmt.Printf(" %8s %s %3.2f\n", r.pkg, bar(x, scale), x)
+		}
+	}
+}
+
+// bar returns a bar x times the scale long using partial blocks for the
+// fraction.
+func bar(x float64, scale int) string {
+	size := x * float64(scale)
+	b := strings.Repeat(string([]rune(blocks)[8:]), int(size))
+	frac := int(size*8.0) - (int(size) * 8)
+
+	return b + string([]rune(blocks)[frac:frac+1])
+}
+
+// sortResults sorts the results from fastest to slowest. Results with
+// errors are placed last in their original order.
+func sortResults(results []*result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		ci := results[i].call
+		cj := results[j].call
+		if ci.err != nil || cj.err != nil {
+			return ci.err == nil && cj.err != nil
+		}
+		return ci.ns < cj.ns
+	})
+}
+
+// compareBase displays how much faster each package is compared to its own
+// call in the base suite if the base suite has already been run.
+func (s *suite) compareBase(pkgs []*pkg) {
+	var shown bool
+	for _, p := range pkgs {
+		c := p.calls[s.fun]
+		bc := p.calls[s.base]
+		if c == nil || bc == nil || c.err != nil || bc.err != nil || c.ns <= 0 || bc.ns <= 0 {
+			continue
+		}
+		if !shown {
+			fmt.Println()
+			fmt.Printf(" Compared to the %s suite:\n", s.base)
+			shown = true
+		}
+		fmt.Printf(" %8s %6.2f times faster than %s.%s\n", p.name, float64(bc.ns)/float64(c.ns), p.name, bc.name)
+	}
+}
+
+// printPackages sets the version of each package from the build info and
+// displays the module and version used for the results.
+func printPackages(pkgs []*pkg) {
+	fmt.Println()
+	fmt.Println("Packages compared")
+	for _, p := range pkgs {
+		p.version = moduleVersion(p.module)
+		fmt.Printf(" %8s %-30s %s\n", p.name, p.module, p.version)
+	}
+}
+
+// printTable displays rows of cells in columns wide enough for the widest cell
+// in each column. The first row is the header.
+func printTable(header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if widths[i] < len(cell) {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if i == 0 {
+				fmt.Printf(" %-*s", widths[i], cell)
 			} else {
-				fmt.Printf(" %8s >>> %s <<<\n", r.pkg, c.err)
-				continue
+				fmt.Printf("  %*s", widths[i], cell)
 			}
 		}
-		fmt.Printf(" %8s %s %3.2f\n", r.pkg, bar, x)
+		fmt.Println()
 	}
 }
 
@@ -200,6 +451,47 @@ func loadSample() (data interface{}) {
 	return
 }
 
+// loadStrictSample returns the sample without the extension valueString
+// members since the Extension struct has no field for them and they would be
+// rejected when unknown fields are disallowed.
+func loadStrictSample() []byte {
+	return []byte(oj.JSON(strictValue(loadSample()), 2))
+}
+
+func strictValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		delete(tv, "valueString")
+		for _, m := range tv {
+			strictValue(m)
+		}
+	case []interface{}:
+		for _, m := range tv {
+			strictValue(m)
+		}
+	}
+	return v
+}
+
+// loadUnknownSample returns the strict sample with extra members added that
+// do not match any field in the Patient struct.
+func loadUnknownSample() []byte {
+	data := strictValue(loadSample())
+	patient, _ := data.(map[string]interface{})
+	if patient == nil {
+		log.Fatalf("Expected %s to be a JSON object.\n", filename)
+	}
+	patient["extra"] = map[string]interface{}{"note": "not a Patient field", "level": 3}
+	if names, ok := patient["name"].([]interface{}); ok && 0 < len(names) {
+		if name, ok := names[0].(map[string]interface{}); ok {
+			name["suffix"] = []interface{}{"Jr."}
+		}
+	}
+	patient["unknownFlag"] = true
+
+	return []byte(oj.JSON(patient))
+}
+
 func openSmallLogFile() *os.File {
 	f, err := os.Open(smallLogFile)
 	if err != nil {
@@ -268,72 +560,3 @@ func createLogFile(filename string, size int) error {
 	}
 	return nil
 }
-
-func getSpecs() (s *specs) {
-	// Assume MacOS and try system_profiler. If that fails assume linux and check /proc.
-	out, err := exec.Command("system_profiler", "-json", "SPHardwareDataType").Output()
-	if err == nil {
-		var js interface{}
-		if js, err = oj.Parse(out); err == nil {
-			s = &specs{
-				model:     alt.String(jp.C("SPHardwareDataType").N(0).C("machine_model").First(js)),
-				processor: alt.String(jp.C("SPHardwareDataType").N(0).C("cpu_type").First(js)),
-				cores:     alt.String(jp.C("SPHardwareDataType").N(0).C("number_processors").First(js)),
-				speed:     alt.String(jp.C("SPHardwareDataType").N(0).C("current_processor_speed").First(js)),
-				memory:    alt.String(jp.C("SPHardwareDataType").N(0).C("physical_memory").First(js)),
-			}
-			var b []byte
-			if out, err = exec.Command("sw_vers", "-productName").Output(); err == nil {
-				b = append(b, bytes.TrimSpace(out)...)
-				b = append(b, ' ')
-			}
-			if out, err = exec.Command("sw_vers", "-productVersion").Output(); err == nil {
-				b = append(b, bytes.TrimSpace(out)...)
-			}
-			s.os = string(b)
-		}
-		return
-	}
-	// Try Ubuntu next.
-	if out, err = exec.Command("lsb_release", "-d").Output(); err == nil {
-		s = &specs{}
-		parts := strings.Split(string(out), ":")
-		if 1 < len(parts) {
-			s.os = string(strings.TrimSpace(parts[1]))
-		}
-		if out, err = ioutil.ReadFile("/proc/cpuinfo"); err == nil {
-			cnt := 0
-			for _, line := range strings.Split(string(out), "\n") {
-				if strings.Contains(line, "processor") {
-					cnt++
-				} else if strings.Contains(line, "model name") {
-					parts := strings.Split(line, ":")
-					if 1 < len(parts) {
-						parts = strings.Split(parts[1], "@")
-						s.processor = strings.TrimSpace(parts[0])
-						if 1 < len(parts) {
-							s.speed = strings.TrimSpace(parts[1])
-						}
-					}
-				}
-				s.cores = fmt.Sprintf("%d", cnt)
-			}
-		}
-		if out, err = ioutil.ReadFile("/proc/meminfo"); err == nil {
-			for _, line := range strings.Split(string(out), "\n") {
-				if strings.Contains(line, "MemTotal") {
-					parts := strings.Split(line, ":")
-					if 1 < len(parts) {
-						s.memory = strings.TrimSpace(parts[1])
-						if strings.HasSuffix(s.memory, "kB") {
-							if i, err := strconv.Atoi(strings.Split(s.memory, " ")[0]); err == nil {
-								s.memory = fmt.Sprintf("%d GB", i/1000000)
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-	return
-}
@@ -0,0 +1,206 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureOutput returns what f writes to stdout.
+func captureOutput(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		done <- string(b)
+	}()
+	defer func() { os.Stdout = orig }()
+	f()
+	_ = w.Close()
+
+	return <-done
+}
+
+// setBenchtime sets the benchtime used by testing.Benchmark for the rest of
+// the test.
+func setBenchtime(t *testing.T, value string) {
+	f := flag.Lookup("test.benchtime")
+	orig := f.Value.String()
+	if err := f.Value.Set(value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Value.Set(orig) })
+}
+
+func TestBar(t *testing.T) {
+	for _, tc := range []struct {
+		x      float64
+		scale  int
+		expect string
+	}{
+		{x: 0.0, scale: 7, expect: " "},
+		{x: 1.0, scale: 7, expect: "███████ "},
+		{x: 0.5, scale: 7, expect: "███▌"},
+		{x: 2.0, scale: 7, expect: "██████████████ "},
+		{x: 0.125, scale: 8, expect: "█ "},
+		{x: 1.0 / 64.0, scale: 8, expect: "▏"},
+		{x: 0.99, scale: 1, expect: "▉"},
+	} {
+		if got := bar(tc.x, tc.scale); got != tc.expect {
+			t.Errorf("bar(%g, %d) expected %q, got %q", tc.x, tc.scale, tc.expect, got)
+		}
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	results := []*result{
+		{pkg: "c", call: &call{ns: 300}},
+		{pkg: "x", call: &call{ns: math.MaxInt64, err: errors.New("failed")}},
+		{pkg: "a", call: &call{ns: 100}},
+		{pkg: "y", call: &call{ns: math.MaxInt64, err: errors.New("not supported")}},
+		{pkg: "z", call: &call{ns: 50, err: errors.New("failed after setting ns")}},
+		{pkg: "b", call: &call{ns: 200}},
+	}
+	sortResults(results)
+	var order []string
+	for _, r := range results {
+		order = append(order, r.pkg)
+	}
+	if got := strings.Join(order, ","); got != "a,b,c,x,y,z" {
+		t.Errorf("expected a,b,c,x,y,z, got %s", got)
+	}
+}
+
+func TestChart(t *testing.T) {
+	s := suite{fun: "fake", ref: "ref"}
+	ref := &call{ns: 200}
+	results := []*result{
+		{pkg: "ref", call: ref, ref: true},
+		{pkg: "slow", call: &call{ns: 400}},
+		{pkg: "broken", call: &call{ns: math.MaxInt64, err: errors.New("broken")}},
+		{pkg: "fast", call: &call{ns: 100}},
+	}
+	out := captureOutput(t, func() { s.chart(results, ref) })
+	expect := `     fast ██████████████  2.00
+      ref ▓▓▓▓▓▓▓ 1.00
+     slow ███▌ 0.50
+   broken >>> broken <<<
+`
+	if out != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, out)
+	}
+}
+
+func TestChartReferenceFailed(t *testing.T) {
+	s := suite{fun: "fake", ref: "ref"}
+	ref := &call{ns: math.MaxInt64, err: errors.New("out of memory")}
+	results := []*result{
+		{pkg: "ref", call: ref, ref: true},
+		{pkg: "other", call: &call{ns: 100}},
+	}
+	out := captureOutput(t, func() { s.chart(results, ref) })
+	expect := `    other >>> no ref result to compare to <<<
+      ref >>> out of memory <<<
+`
+	if out != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, out)
+	}
+}
+
+func TestSuiteExec(t *testing.T) {
+	setBenchtime(t, "10x")
+	var sink int
+	work := func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			sink += n
+		}
+	}
+	pkgs := []*pkg{
+		{name: "ref", calls: map[string]*call{"fake": {name: "Run", fun: work}}},
+		{name: "missing", calls: map[string]*call{}},
+		{name: "failing", calls: map[string]*call{"fake": {name: "Fail", fun: func(b *testing.B) {
+			benchErr = errors.New("fake failure")
+			b.Fail()
+		}}}},
+		{name: "other", calls: map[string]*call{"fake": {name: "Run", fun: work}}},
+	}
+	s := suite{fun: "fake", title: "Fake suite", ref: "ref"}
+	out := captureOutput(t, func() { s.exec(pkgs) })
+
+	for _, expect := range []string{
+		"Fake suite\n",
+		"      ref.Run         ",
+		"  missing >>> not supported <<<\n",
+		"  failing.Fail        >>> fake failure <<<\n",
+		"      ref ▓▓▓▓▓▓▓ 1.00\n",
+		"  failing >>> fake failure <<<\n",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("expected output to contain %q\n%s", expect, out)
+		}
+	}
+	if c := pkgs[2].calls["fake"]; c.err == nil || c.err.Error() != "fake failure" {
+		t.Errorf("expected the error to be set on the call, got %v", c.err)
+	}
+	if c := pkgs[3].calls["fake"]; c.err != nil || c.res.N != 10 {
+		t.Errorf("expected 10 iterations without an error, got %d and %v", c.res.N, c.err)
+	}
+	// The missing and failing packages are last, in package order.
+	if strings.Index(out, "  missing >>> not supported") > strings.LastIndex(out, "  failing >>> fake failure") {
+		t.Errorf("expected errors in package order\n%s", out)
+	}
+}
+
+func TestCreateLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	if err := createLogFile(path, 1); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var cnt int
+	var lineSize int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !json.Valid(line) {
+			t.Fatalf("line %d is not valid JSON: %s", cnt+1, line)
+		}
+		if lineSize == 0 {
+			lineSize = len(line) + 1
+		}
+		cnt++
+	}
+	if err = scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	size := int64(1024 * 1024)
+	if size < fi.Size() || fi.Size() <= size-int64(lineSize) {
+		t.Errorf("expected a file size within one entry (%d bytes) of %d, got %d", lineSize, size, fi.Size())
+	}
+	if int64(cnt*lineSize) != fi.Size() {
+		t.Errorf("expected %d entries of %d bytes, file size is %d", cnt, lineSize, fi.Size())
+	}
+}
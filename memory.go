@@ -0,0 +1,221 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// childResult is written by a child process as the last line of output and
+// read by the parent.
+type childResult struct {
+	N        int
+	Ns       int64
+	Bytes    int64
+	Allocs   int64
+	PeakHeap uint64
+	GCs      uint64
+	GC       gcStats
+	Version  string
+	Err      string
+}
+
+// runIsolated runs the call in a child process so that a package that runs
+// out of memory does not take down the whole run and so peak memory use can
+// be measured for each package. The module version reported by the child is
+// returned.
+func (s *suite) runIsolated(p *pkg, c *call) string {
+	// The suite benchtime is passed after the command line options so it
+	// replaces any given on the command line.
+	args := childArgs("-child", s.fun+"/"+p.name, "-test.benchtime="+flag.Lookup("test.benchtime").Value.String())
+	cmd := exec.Command(os.Args[0], args...)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	c.peakRSS = peakRSS(cmd.ProcessState)
+	if err != nil {
+		if isOutOfMemory(cmd.ProcessState, stderr.String()) {
+			c.err = fmt.Errorf("out of memory at peak RSS %s", memSize(c.peakRSS))
+		} else {
+			c.err = fmt.Errorf("%s %s", err, firstLine(stderr.String()))
+		}
+		return p.version
+	}
+	var cr childResult
+	if err = json.Unmarshal([]byte(lastLine(stdout.String())), &cr); err != nil {
+		c.err = fmt.Errorf("bad child result. %s", err)
+		return p.version
+	}
+	if 0 < len(cr.Err) {
+		c.err = fmt.Errorf("%s", cr.Err)
+		return p.version
+	}
+	c.res.N = cr.N
+	c.ns = cr.Ns
+	c.bytes = cr.Bytes
+	c.allocs = cr.Allocs
+	c.peakHeap = int64(cr.PeakHeap)
+	c.gcs = int64(cr.GCs)
+	c.gc = cr.GC
+
+	return cr.Version
+}
+
+// childArgs returns the arguments for a child process. The options given on
+// the command line are followed by the extra options and then the remaining
+// command line arguments since options after the first argument are not
+// parsed.
+func childArgs(option, id string, extra ...string) []string {
+	given := os.Args[1:]
+	given = given[:len(given)-flag.NArg()]
+	args := append([]string{option, id}, given...)
+	args = append(args, extra...)
+
+	return append(args, flag.Args()...)
+}
+
+// peakRSS returns the maximum resident set size of a process that has
+// exited.
+func peakRSS(ps *os.ProcessState) (rss int64) {
+	if ps != nil {
+		if ru, ok := ps.SysUsage().(*syscall.Rusage); ok {
+			rss = int64(ru.Maxrss)
+			if runtime.GOOS != "darwin" { // darwin reports bytes, linux KB
+				rss *= 1024
+			}
+		}
+	}
+	return
+}
+
+// limitMemory limits the memory of the current process to mb megabytes.
+func limitMemory(mb int) {
+	limit := int64(mb) * 1024 * 1024
+	debug.SetMemoryLimit(limit)
+	// RLIMIT_DATA is used instead of RLIMIT_AS since the go runtime
+	// reserves much more address space than it uses.
+	_ = syscall.Setrlimit(syscall.RLIMIT_DATA, &syscall.Rlimit{Cur: uint64(limit), Max: uint64(limit)})
+}
+
+// isOutOfMemory returns true if the child process was killed by the OOM
+// killer or the go runtime reported that it ran out of memory.
+func isOutOfMemory(ps *os.ProcessState, stderr string) bool {
+	if ps != nil {
+		if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() && ws.Signal() == syscall.SIGKILL {
+			return true
+		}
+	}
+	return strings.Contains(stderr, "out of memory") || strings.Contains(stderr, "cannot allocate memory")
+}
+
+// runChild runs a single call identified by suite/package and writes the
+// result as JSON to stdout. It is called when the -child option is given.
+func runChild(pkgs []*pkg, id string) {
+	var c *call
+	var fun, name, module string
+	for _, p := range pkgs {
+		for f, pc := range p.calls {
+			if f+"/"+p.name == id {
+				c = pc
+				fun = f
+				name = p.name
+				module = p.module
+			}
+		}
+	}
+	cr := childResult{Version: moduleVersion(module)}
+	if c == nil {
+		cr.Err = fmt.Sprintf("%s not found", id)
+		writeChildResult(&cr)
+		return
+	}
+	if 0 < memLimit {
+		limitMemory(memLimit)
+	}
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/heap/objects:bytes"},
+		{Name: "/gc/cycles/total:gc-cycles"},
+	}
+	metrics.Read(samples)
+	startGCs := samples[1].Value.Uint64()
+	done := make(chan bool)
+	peak := make(chan uint64)
+	go func() {
+		var max uint64
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				peak <- max
+				return
+			case <-ticker.C:
+				metrics.Read(samples[:1])
+				if v := samples[0].Value.Uint64(); max < v {
+					max = v
+				}
+			}
+		}
+	}()
+	prepare(c)
+	var res testing.BenchmarkResult
+	profileRun(profileName(fun, name), func() {
+		res = testing.Benchmark(measureGC(c.fun, &cr.GC))
+	})
+	done <- true
+	cr.PeakHeap = <-peak
+	metrics.Read(samples)
+	cr.GCs = samples[1].Value.Uint64() - startGCs
+	if benchErr != nil {
+		cr.Err = benchErr.Error()
+	} else {
+		cr.N = res.N
+		cr.Ns = res.NsPerOp()
+		cr.Bytes = res.AllocedBytesPerOp()
+		cr.Allocs = res.AllocsPerOp()
+	}
+	writeChildResult(&cr)
+}
+
+func writeChildResult(cr *childResult) {
+	j, _ := json.Marshal(cr)
+	fmt.Println()
+	fmt.Println(string(j))
+}
+
+func firstLine(s string) string {
+	return strings.Split(strings.TrimSpace(s), "\n")[0]
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
+
+// memSize returns a human readable size.
+func memSize(n int64) string {
+	switch {
+	case n < 0 || n == math.MaxInt64:
+		return "--"
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024.0)
+	case n < 1024*1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024.0*1024.0))
+	}
+	return fmt.Sprintf("%.2f GB", float64(n)/(1024.0*1024.0*1024.0))
+}
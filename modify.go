@@ -0,0 +1,151 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"testing"
+)
+
+// modifyOp is a change made to the sample by the modify suite. The value at
+// the path is replaced with value or deleted if value is nil. Path elements
+// are the same as for the extract suites.
+type modifyOp struct {
+	path  []interface{}
+	value interface{}
+}
+
+// modifyOps are the changes made to the sample. Only strings and booleans
+// are used as values.
+var modifyOps = []modifyOp{
+	{path: []interface{}{"active"}, value: false},
+	{path: []interface{}{"name", 0, "family"}, value: "Smith"},
+	{path: []interface{}{"address", 0, "city"}, value: "Springfield"},
+	{path: []interface{}{"birthDate"}, value: "1974-12-26"},
+	{path: []interface{}{"text"}},
+	{path: []interface{}{"contact", 0, "telecom"}},
+}
+
+// modifyApply makes the modify changes to generic data decoded from the
+// sample.
+func modifyApply(v interface{}) error {
+	for _, op := range modifyOps {
+		parent := v
+		for _, p := range op.path[:len(op.path)-1] {
+			parent = modifyChild(parent, p)
+		}
+		last := op.path[len(op.path)-1]
+		switch tp := parent.(type) {
+		case map[string]interface{}:
+			key, _ := last.(string)
+			if _, has := tp[key]; !has {
+				return fmt.Errorf("%v not found", op.path)
+			}
+			if op.value == nil {
+				delete(tp, key)
+			} else {
+				tp[key] = op.value
+			}
+		case []interface{}:
+			i, ok := last.(int)
+			if !ok || i < 0 || len(tp) <= i || op.value == nil {
+				return fmt.Errorf("%v can not be modified", op.path)
+			}
+			tp[i] = op.value
+		default:
+			return fmt.Errorf("%v not found", op.path)
+		}
+	}
+	return nil
+}
+
+func modifyChild(v interface{}, p interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		if key, ok := p.(string); ok {
+			return tv[key]
+		}
+	case []interface{}:
+		if i, ok := p.(int); ok && 0 <= i && i < len(tv) {
+			return tv[i]
+		}
+	}
+	return nil
+}
+
+// modifyCanonical returns JSON in a canonical form that is compact with
+// sorted keys, without HTML escaping, and with numbers written as they appear
+// in the JSON. Outputs in the canonical form can be compared byte for byte.
+func modifyCanonical(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// modifyExpect returns the modified sample in the canonical form.
+func modifyExpect() []byte {
+	v := loadSample()
+	if err := modifyApply(v); err != nil {
+		log.Fatalf("Failed to modify %s. %s\n", filename, err)
+	}
+	j, err := json.Marshal(v)
+	if err == nil {
+		j, err = modifyCanonical(j)
+	}
+	if err != nil {
+		log.Fatalf("Failed to modify %s. %s\n", filename, err)
+	}
+	return j
+}
+
+// modifyCheck returns an error if the modified output in the canonical form
+// is not the same as the expected output.
+func modifyCheck(out []byte, expect []byte) error {
+	canonical, err := modifyCanonical(out)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(canonical, expect) {
+		return fmt.Errorf("modified output differs from the expected output")
+	}
+	return nil
+}
+
+// benchModify verifies the modify function output and then benchmarks the
+// read, modify, and write cycle on the sample.
+func benchModify(b *testing.B, modify func(data []byte) ([]byte, error)) {
+	sample, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Fatalf("Failed to read %s. %s\n", filename, err)
+	}
+	out, err := modify(sample)
+	if err == nil {
+		err = modifyCheck(out, modifyExpect())
+	}
+	if err != nil {
+		benchErr = err
+		b.Fail()
+		return
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, benchErr = modify(sample); benchErr != nil {
+			b.Fail()
+			break
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+)
+
+func TestModifyApply(t *testing.T) {
+	v := loadSample()
+	if err := modifyApply(v); err != nil {
+		t.Fatalf("modify failed. %s", err)
+	}
+	for _, op := range modifyOps {
+		got := pathExpr(op.path).Get(v)
+		switch {
+		case op.value == nil && len(got) != 0:
+			t.Errorf("%v not deleted", op.path)
+		case op.value != nil && (len(got) != 1 || got[0] != op.value):
+			t.Errorf("%v is %v, expected %v", op.path, got, op.value)
+		}
+	}
+	if err := modifyApply(v); err == nil {
+		t.Errorf("modifying deleted paths did not fail")
+	}
+	if err := modifyApply(map[string]interface{}{"name": []interface{}{}}); err == nil {
+		t.Errorf("modifying a missing path did not fail")
+	}
+}
+
+func TestModifyCheck(t *testing.T) {
+	sample, _ := ioutil.ReadFile(filename)
+	expect := modifyExpect()
+	if err := modifyCheck(sample, expect); err == nil {
+		t.Errorf("unmodified sample not detected")
+	}
+	v := loadSample()
+	_ = modifyApply(v)
+	if err := modifyCheck([]byte(oj.JSON(v, &ojg.Options{Indent: 2})), expect); err != nil {
+		t.Errorf("modified sample check failed. %s", err)
+	}
+	if err := modifyCheck(bytes.Replace(expect, []byte(`"rank":1`), []byte(`"rank":1.0`), 1), expect); err == nil {
+		t.Errorf("changed number format not detected")
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// numberCorpus is a set of JSON numbers that are decoded differently or
+// lose precision with some packages.
+var numberCorpus = []string{
+	"0",
+	"-0",
+	"-0.0",
+	"1.0",
+	"0.1",
+	"0.30000000000000004",
+	"9007199254740991",     // 2^53 - 1
+	"9007199254740993",     // 2^53 + 1
+	"9223372036854775807",  // max int64
+	"-9223372036854775808", // min int64
+	"9223372036854775808",  // max int64 + 1
+	"18446744073709551615", // max uint64
+	"18446744073709551616", // max uint64 + 1
+	"123456789012345678901234567890",
+	"3.141592653589793238462643383279",
+	"1e2",
+	"1E+2",
+	"1.5e-3",
+	"-12.5E-10",
+	"1.7976931348623157e308",
+	"5e-324",
+	"1e400",
+	"1e-400",
+}
+
+// numberSampleSize is the number of values in the number-heavy sample used
+// by the numbers suites.
+const numberSampleSize = 1000
+
+// numberSample returns a JSON array of numbers taken from the corpus. Numbers
+// that all packages reject, such as 1e400, are left out.
+func numberSample() []byte {
+	var usable []string
+	for _, num := range numberCorpus {
+		var f float64
+		if json.Unmarshal([]byte(num), &f) == nil {
+			usable = append(usable, num)
+		}
+	}
+	nums := make([]string, numberSampleSize)
+	for i := range nums {
+		nums[i] = usable[i%len(usable)]
+	}
+	return []byte("[" + strings.Join(nums, ",") + "]")
+}
+
+// numberPrecision decodes each number in the corpus with each package's
+// parse function and displays the resulting go type and value along with
+// whether the value is exact.
+func numberPrecision(pkgs []*pkg) {
+	header := []string{"number", "package", "type", "value", "result"}
+	var rows [][]string
+	for _, num := range numberCorpus {
+		expect, _ := new(big.Rat).SetString(num)
+		first := num
+		for _, p := range pkgs {
+			if p.parse == nil {
+				continue
+			}
+			row := []string{first, p.name}
+			first = ""
+			v, err := numberParse(p, num)
+			if err != nil {
+				rows = append(rows, append(row, "", clip(err.Error()), "error"))
+				continue
+			}
+			rows = append(rows, append(row, fmt.Sprintf("%T", v), fmt.Sprintf("%v", v), numberExact(num, expect, v)))
+		}
+	}
+	fmt.Println()
+	fmt.Println("Number decoding precision")
+	printTable(header, rows)
+	fmt.Println(" (rounded indicates the closest float64, lossy indicates precision was lost beyond that)")
+}
+
+// numberParse decodes a number wrapped in an array and returns the decoded
+// array element.
+func numberParse(p *pkg, num string) (v interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	if v, err = p.parse([]byte("[" + num + "]")); err != nil {
+		return
+	}
+	if list, ok := v.([]interface{}); ok && len(list) == 1 {
+		return list[0], nil
+	}
+	return nil, fmt.Errorf("expected a one element array, not %v", v)
+}
+
+// numberExact compares the decoded value to the exact value of the number.
+func numberExact(num string, expect *big.Rat, v interface{}) string {
+	var got *big.Rat
+	switch tv := v.(type) {
+	case int64:
+		got = new(big.Rat).SetInt64(tv)
+	case uint64:
+		got = new(big.Rat).SetUint64(tv)
+	case float64:
+		if math.IsInf(tv, 0) || math.IsNaN(tv) {
+			return "lossy"
+		}
+		if tv == 0 && strings.HasPrefix(num, "-") && !math.Signbit(tv) {
+			return "sign lost"
+		}
+		got = new(big.Rat).SetFloat64(tv)
+	case json.Number:
+		got, _ = new(big.Rat).SetString(string(tv))
+	case string:
+		got, _ = new(big.Rat).SetString(tv)
+	}
+	switch {
+	case got == nil || expect == nil:
+		return "unknown"
+	case got.Cmp(expect) == 0:
+		return "exact"
+	}
+	// A float64 that is the closest float64 to a decimal number is as good
+	// as a float64 can be so it is considered rounded and not lossy. Integers
+	// are expected to be exact.
+	if f, ok := v.(float64); ok && strings.ContainsAny(num, ".eE") {
+		if closest, err := strconv.ParseFloat(num, 64); err == nil && closest == f {
+			return "rounded"
+		}
+	}
+	return "lossy"
+}
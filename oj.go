@@ -3,28 +3,74 @@
 package main
 
 import (
+	"errors"
 	"io/ioutil"
 	"log"
 	"os"
+	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
 	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
 )
 
 var ojPkg = pkg{
-	name: "oj",
+	name:   "oj",
+	module: "github.com/ohler55/ojg",
 	calls: map[string]*call{
 		"parse":            {name: "Parse", fun: ojParse},
+		"parse-error":      {name: "Parse", fun: ojParseError},
+		"numbers":          {name: "Parse", fun: ojParseNumbers},
 		"validate":         {name: "Validate", fun: ojValidate},
 		"decode":           {name: "Tokenize", fun: ojTokenize},
+		"callback":         {name: "Tokenize", fun: ojCallback},
 		"unmarshal-struct": {name: "Unmarshal", fun: ojUnmarshalPatient},
 		"marshal":          {name: "JSON", fun: ojJSON},
 		"marshal-struct":   {name: "Marshal", fun: ojMarshalPatient},
+		"decompose":        {name: "alt.Decompose", fun: ojDecompose},
+		"recompose":        {name: "alt.Recompose", fun: ojRecompose},
+		"build":            {name: "Builder", fun: ojBuild},
+		"modify":           {name: "jp.Expr.Set/Del", fun: ojModify},
+		"sen-parse":        {name: "sen.Parse", fun: ojSENParse},
+		"sen-write":        {name: "sen.Writer", fun: ojSENWrite},
+		"marshal-floats":   {name: "Marshal", fun: ojMarshalFloats},
+		"marshal-html":     {name: "Marshal", fun: ojMarshalHTML},
+		"marshal-no-html":  {name: "Marshal", fun: ojMarshalNoHTML},
+		"extract-fields":   {name: "Get", fun: ojExtractFields},
+		"extract-log":      {name: "Get", fun: ojExtractLog},
 		"file1":            {name: "ParseReader", fun: ojFile1},
 		"small-file":       {name: "ParseReader", fun: ojFileManySmallLoad},
 		"large-file":       {name: "ParseReader", fun: ojFileManyLarge},
 	},
+	parse: func(data []byte) (interface{}, error) {
+		return oj.Parse(data)
+	},
+	validate: func(data []byte) error {
+		return oj.Validate(data)
+	},
+	marshal:       ojMarshal,
+	marshalEscape: ojMarshalEscape,
+	unmarshal: func(data []byte, v interface{}) error {
+		return oj.Unmarshal(data, v)
+	},
+	errorOffset: func(data []byte, err error) int {
+		var pe *oj.ParseError
+		if errors.As(err, &pe) {
+			return lineColumnOffset(data, pe.Line, pe.Column)
+		}
+		return -1
+	},
+}
+
+func ojMarshal(v interface{}) ([]byte, error) {
+	j, err := oj.Marshal(v)
+	// oj.Marshal returns the buffer of a pooled writer so make a copy before
+	// the writer is reused.
+	return append([]byte{}, j...), err
 }
 
 func ojParse(b *testing.B) {
@@ -38,6 +84,33 @@ func ojParse(b *testing.B) {
 	}
 }
 
+func ojParseError(b *testing.B) {
+	sample, _ := errorSample()
+	b.ResetTimer()
+	p := &oj.Parser{Reuse: true}
+	for n := 0; n < b.N; n++ {
+		if _, err := p.Parse(sample); err == nil {
+			benchErr = errors.New("error not detected")
+			b.Fail()
+		}
+	}
+}
+
+// ojParseNumbers parses the number sample. By default oj returns integers as
+// int64 and numbers too large for an int64 or float64 as a json.Number but
+// there is no option to keep all numbers exact so oj is not included in the
+// numbers-exact suite.
+func ojParseNumbers(b *testing.B) {
+	sample := numberSample()
+	b.ResetTimer()
+	p := &oj.Parser{Reuse: true}
+	for n := 0; n < b.N; n++ {
+		if _, benchErr = p.Parse(sample); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
+
 func ojValidate(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	b.ResetTimer()
@@ -61,14 +134,76 @@ func ojUnmarshalPatient(b *testing.B) {
 	}
 }
 
+// ojCountHandler counts the tokens passed to it.
+type ojCountHandler struct {
+	cnt int
+}
+
+func (h *ojCountHandler) Null()         { h.cnt++ }
+func (h *ojCountHandler) Bool(bool)     { h.cnt++ }
+func (h *ojCountHandler) Int(int64)     { h.cnt++ }
+func (h *ojCountHandler) Float(float64) { h.cnt++ }
+func (h *ojCountHandler) Number(string) { h.cnt++ }
+func (h *ojCountHandler) String(string) { h.cnt++ }
+func (h *ojCountHandler) ObjectStart()  { h.cnt++ }
+func (h *ojCountHandler) ObjectEnd()    { h.cnt++ }
+func (h *ojCountHandler) Key(string)    { h.cnt++ }
+func (h *ojCountHandler) ArrayStart()   { h.cnt++ }
+func (h *ojCountHandler) ArrayEnd()     { h.cnt++ }
+
 func ojTokenize(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
+	expect := tokenExpect(sample)
 	b.ResetTimer()
-	h := oj.ZeroHandler{}
+	h := ojCountHandler{}
 	t := oj.Tokenizer{}
 	for n := 0; n < b.N; n++ {
-		if err := t.Parse(sample, &h); err != nil {
-			log.Fatal(err)
+		h.cnt = 0
+		if benchErr = t.Parse(sample, &h); benchErr == nil {
+			benchErr = tokenCheck(h.cnt, expect)
+		}
+		if benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+// ojCollector is a TokenHandler that feeds the tokens to a collector.
+type ojCollector struct {
+	collector
+}
+
+func (h *ojCollector) Null()           {}
+func (h *ojCollector) Bool(bool)       {}
+func (h *ojCollector) Int(i int64)     { h.num(float64(i)) }
+func (h *ojCollector) Float(f float64) { h.num(f) }
+func (h *ojCollector) String(s string) { h.str(s) }
+func (h *ojCollector) ObjectStart()    { h.start() }
+func (h *ojCollector) ObjectEnd()      { h.end() }
+func (h *ojCollector) Key(k string)    { h.key(k) }
+func (h *ojCollector) ArrayStart()     { h.start() }
+func (h *ojCollector) ArrayEnd()       { h.end() }
+
+func (h *ojCollector) Number(s string) {
+	f, _ := strconv.ParseFloat(s, 64)
+	h.num(f)
+}
+
+func ojCallback(b *testing.B) {
+	sample, _ := ioutil.ReadFile(filename)
+	expect := collectExpect(sample)
+	b.ResetTimer()
+	h := ojCollector{}
+	t := oj.Tokenizer{}
+	for n := 0; n < b.N; n++ {
+		h.reset()
+		if benchErr = t.Parse(sample, &h); benchErr == nil {
+			benchErr = h.check(expect)
+		}
+		if benchErr != nil {
+			b.Fail()
+			return
 		}
 	}
 }
@@ -82,6 +217,344 @@ func ojJSON(b *testing.B) {
 	}
 }
 
+// ojMarshalEscape returns a function that marshals with a Writer that has
+// HTMLUnsafe set to the opposite of escapeHTML. There is no option to escape
+// non-ASCII characters.
+func ojMarshalEscape(escapeHTML, escapeASCII bool) func(v interface{}) ([]byte, error) {
+	if escapeASCII {
+		return nil
+	}
+	wr := &oj.Writer{Options: ojg.GoOptions}
+	wr.HTMLUnsafe = !escapeHTML
+	// Keys are sorted as they are by encoding/json.
+	wr.Sort = true
+	return func(v interface{}) ([]byte, error) {
+		j, err := oj.Marshal(v, wr)
+		// The writer buffer is reused so make a copy.
+		return append([]byte{}, j...), err
+	}
+}
+
+func ojMarshalHTML(b *testing.B) {
+	benchMarshalEscape(b, ojMarshalEscape(true, false), true)
+}
+
+func ojMarshalNoHTML(b *testing.B) {
+	benchMarshalEscape(b, ojMarshalEscape(false, false), false)
+}
+
+func ojMarshalFloats(b *testing.B) {
+	benchMarshalFloats(b, ojMarshal)
+}
+
+// ojSENParse checks that the SEN sample parses to the same value as the JSON
+// sample before timing the parse.
+func ojSENParse(b *testing.B) {
+	sample := senSample()
+	p := &sen.Parser{Reuse: true}
+	if benchErr = senCheck(p, sample, loadSample()); benchErr != nil {
+		b.Fail()
+		return
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, benchErr = p.Parse(sample); benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+// ojSENWrite checks that the written SEN parses back to the sample before
+// timing the write.
+func ojSENWrite(b *testing.B) {
+	data := loadSample()
+	wr := sen.Writer{Options: ojg.Options{OmitNil: true}}
+	if benchErr = senCheck(&sen.Parser{}, []byte(wr.SEN(data)), data); benchErr != nil {
+		b.Fail()
+		return
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = wr.MustSEN(data)
+	}
+}
+
+// senCheck returns an error if the SEN does not parse to the expected value.
+func senCheck(p *sen.Parser, src []byte, expect interface{}) error {
+	v, err := p.Parse(src)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(v, expect) {
+		return errors.New("parsed SEN differs from the sample")
+	}
+	return nil
+}
+
+// ojDecompose uses the GoOptions so json tags are used for keys and no type
+// key is added to objects as with the default options.
+func ojDecompose(b *testing.B) {
+	benchDecompose(b, func(patient *Patient) (interface{}, error) {
+		return alt.Decompose(patient, &ojg.GoOptions), nil
+	})
+}
+
+func ojRecompose(b *testing.B) {
+	benchRecompose(b, func(v interface{}) (*Patient, error) {
+		var patient Patient
+		_, err := alt.Recompose(v, &patient)
+		return &patient, err
+	})
+}
+
+// ojBuild builds the build document with a Builder and writes it with sorted
+// keys. In ojg v1.11.1 Builder.Pop does not remove an object from the stack
+// (see Builder.Pop in alt/builder.go of github.com/ohler55/ojg) so members
+// added after a nested object end up in the nested object. Each nested
+// object is built with the Builder for its depth instead and then added to
+// the parent as a value.
+func ojBuild(b *testing.B) {
+	var builders [5]oj.Builder
+	wr := oj.Writer{Options: ojg.Options{Sort: true, HTMLUnsafe: true}}
+	benchBuild(b, func() []byte {
+		d0, d1, d2, d3, d4 := &builders[0], &builders[1], &builders[2], &builders[3], &builders[4]
+		d0.Reset()
+		_ = d0.Object()
+
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Array("extension")
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Value("http://hl7.org/fhir/StructureDefinition/patient-birthTime", "url")
+		_ = d2.Value("1974-12-25T14:35:45-05:00", "valueDateTime")
+		_ = d1.Value(d2.Result())
+		d1.Pop()
+		_ = d0.Value(d1.Result(), "_birthDate")
+		_ = d0.Value(true, "active")
+
+		_ = d0.Array("address")
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Value("PleasantVille", "city")
+		_ = d1.Value("Rainbow", "district")
+		_ = d1.Array("line")
+		_ = d1.Value("534 Erewhon St")
+		d1.Pop()
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Value("1974-12-25", "start")
+		_ = d1.Value(d2.Result(), "period")
+		_ = d1.Value("3999", "postalCode")
+		_ = d1.Value("Vic", "state")
+		_ = d1.Value("534 Erewhon St PeasantVille, Rainbow, Vic  3999", "text")
+		_ = d1.Value("both", "type")
+		_ = d1.Value("home", "use")
+		_ = d0.Value(d1.Result())
+		d0.Pop()
+		_ = d0.Value("1974-12-25", "birthDate")
+
+		_ = d0.Array("contact")
+		d1.Reset()
+		_ = d1.Object()
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Value("PleasantVille", "city")
+		_ = d2.Value("Rainbow", "district")
+		_ = d2.Array("line")
+		_ = d2.Value("534 Erewhon St")
+		d2.Pop()
+		d3.Reset()
+		_ = d3.Object()
+		_ = d3.Value("1974-12-25", "start")
+		_ = d2.Value(d3.Result(), "period")
+		_ = d2.Value("3999", "postalCode")
+		_ = d2.Value("Vic", "state")
+		_ = d2.Value("both", "type")
+		_ = d2.Value("home", "use")
+		_ = d1.Value(d2.Result(), "address")
+		_ = d1.Value("female", "gender")
+		d2.Reset()
+		_ = d2.Object()
+		d3.Reset()
+		_ = d3.Object()
+		_ = d3.Array("extension")
+		d4.Reset()
+		_ = d4.Object()
+		_ = d4.Value("http://hl7.org/fhir/StructureDefinition/humanname-own-prefix", "url")
+		_ = d4.Value("VV", "valueString")
+		_ = d3.Value(d4.Result())
+		d3.Pop()
+		_ = d2.Value(d3.Result(), "_family")
+		_ = d2.Value("du Marché", "family")
+		_ = d2.Array("given")
+		_ = d2.Value("Bénédicte")
+		d2.Pop()
+		_ = d1.Value(d2.Result(), "name")
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Value("2012", "start")
+		_ = d1.Value(d2.Result(), "period")
+		_ = d1.Array("relationship")
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Array("coding")
+		d3.Reset()
+		_ = d3.Object()
+		_ = d3.Value("N", "code")
+		_ = d3.Value("http://terminology.hl7.org/CodeSystem/v2-0131", "system")
+		_ = d2.Value(d3.Result())
+		d2.Pop()
+		_ = d1.Value(d2.Result())
+		d1.Pop()
+		_ = d1.Array("telecom")
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Value("phone", "system")
+		_ = d2.Value("+33 (237) 998327", "value")
+		_ = d1.Value(d2.Result())
+		d1.Pop()
+		_ = d0.Value(d1.Result())
+		d0.Pop()
+		_ = d0.Value(false, "deceasedBoolean")
+		_ = d0.Value("male", "gender")
+		_ = d0.Value("example", "id")
+
+		_ = d0.Array("identifier")
+		d1.Reset()
+		_ = d1.Object()
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Value("Acme Healthcare", "display")
+		_ = d1.Value(d2.Result(), "assigner")
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Value("2001-05-06", "start")
+		_ = d1.Value(d2.Result(), "period")
+		_ = d1.Value("urn:oid:1.2.36.146.595.217.0.1", "system")
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Array("coding")
+		d3.Reset()
+		_ = d3.Object()
+		_ = d3.Value("MR", "code")
+		_ = d3.Value("http://terminology.hl7.org/CodeSystem/v2-0203", "system")
+		_ = d2.Value(d3.Result())
+		d2.Pop()
+		_ = d1.Value(d2.Result(), "type")
+		_ = d1.Value("usual", "use")
+		_ = d1.Value("12345", "value")
+		_ = d0.Value(d1.Result())
+		d0.Pop()
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Value("Organization/1", "reference")
+		_ = d0.Value(d1.Result(), "managingOrganization")
+
+		_ = d0.Array("name")
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Value("Chalmers", "family")
+		_ = d1.Array("given")
+		_ = d1.Value("Peter")
+		_ = d1.Value("James")
+		d1.Pop()
+		_ = d1.Value("official", "use")
+		_ = d0.Value(d1.Result())
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Array("given")
+		_ = d1.Value("Jim")
+		d1.Pop()
+		_ = d1.Value("usual", "use")
+		_ = d0.Value(d1.Result())
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Value("Windsor", "family")
+		_ = d1.Array("given")
+		_ = d1.Value("Peter")
+		_ = d1.Value("James")
+		d1.Pop()
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Value("2002", "end")
+		_ = d1.Value(d2.Result(), "period")
+		_ = d1.Value("maiden", "use")
+		_ = d0.Value(d1.Result())
+		d0.Pop()
+		_ = d0.Value("Patient", "resourceType")
+
+		_ = d0.Array("telecom")
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Value("home", "use")
+		_ = d0.Value(d1.Result())
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Value(1, "rank")
+		_ = d1.Value("phone", "system")
+		_ = d1.Value("work", "use")
+		_ = d1.Value("(03) 5555 6473", "value")
+		_ = d0.Value(d1.Result())
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Value(2, "rank")
+		_ = d1.Value("phone", "system")
+		_ = d1.Value("mobile", "use")
+		_ = d1.Value("(03) 3410 5613", "value")
+		_ = d0.Value(d1.Result())
+		d1.Reset()
+		_ = d1.Object()
+		d2.Reset()
+		_ = d2.Object()
+		_ = d2.Value("2014", "end")
+		_ = d1.Value(d2.Result(), "period")
+		_ = d1.Value("phone", "system")
+		_ = d1.Value("old", "use")
+		_ = d1.Value("(03) 5555 8834", "value")
+		_ = d0.Value(d1.Result())
+		d0.Pop()
+
+		d1.Reset()
+		_ = d1.Object()
+		_ = d1.Value(buildDiv, "div")
+		_ = d1.Value("generated", "status")
+		_ = d0.Value(d1.Result(), "text")
+
+		return wr.MustJSON(d0.Result())
+	})
+}
+
+// ojModify parses with a reused parser and then sets and deletes values with
+// JSONPath expressions before writing the result.
+func ojModify(b *testing.B) {
+	xs := make([]jp.Expr, len(modifyOps))
+	for i, op := range modifyOps {
+		xs[i] = pathExpr(op.path)
+	}
+	p := &oj.Parser{Reuse: true}
+	benchModify(b, func(data []byte) ([]byte, error) {
+		v, err := p.Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		for i, x := range xs {
+			if modifyOps[i].value == nil {
+				err = x.Del(v)
+			} else {
+				err = x.Set(v, modifyOps[i].value)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		j, err := oj.Marshal(v)
+		// The writer buffer is reused so make a copy.
+		return append([]byte{}, j...), err
+	})
+}
+
 func ojMarshalPatient(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	var patient Patient
@@ -97,6 +570,34 @@ func ojMarshalPatient(b *testing.B) {
 	}
 }
 
+func ojExtractFields(b *testing.B) {
+	benchExtractFields(b, ojExtractor(patientPaths))
+}
+
+func ojExtractLog(b *testing.B) {
+	benchExtractLog(b, ojExtractor(logPaths))
+}
+
+// ojExtractor parses the document and then uses JSONPath expressions to get
+// the values. OjG does not have a lazy parse so the whole document is parsed.
+func ojExtractor(paths [][]interface{}) extractor {
+	xs := make([]jp.Expr, len(paths))
+	for i, path := range paths {
+		xs[i] = pathExpr(path)
+	}
+	p := &oj.Parser{Reuse: true}
+	return func(data []byte, got []string) error {
+		v, err := p.Parse(data)
+		if err != nil {
+			return err
+		}
+		for i, x := range xs {
+			got[i], _ = x.First(v).(string)
+		}
+		return nil
+	}
+}
+
 func ojFile1(b *testing.B) {
 	f, err := os.Open(filename)
 	if err != nil {
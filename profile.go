@@ -0,0 +1,144 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+)
+
+var (
+	cpuProfileDir string
+	memProfileDir string
+	profileTop    int
+)
+
+// profileName returns the base name of the profile files for a suite
+// function and package such as parse-oj.
+func profileName(fun, pkg string) string {
+	return fun + "-" + pkg
+}
+
+func cpuProfilePath(name string) string {
+	return filepath.Join(cpuProfileDir, name+".cpu.pprof")
+}
+
+func memProfilePath(name string) string {
+	return filepath.Join(memProfileDir, name+".mem.pprof")
+}
+
+// profileRun calls run while capturing a CPU and memory profile if the
+// profile directories have been set.
+func profileRun(name string, run func()) {
+	var before []byte
+	if 0 < len(memProfileDir) {
+		before = allocsProfile()
+	}
+	if 0 < len(cpuProfileDir) {
+		if err := os.MkdirAll(cpuProfileDir, 0755); err != nil {
+			log.Fatalf("Failed to create %s. %s\n", cpuProfileDir, err)
+		}
+		f, err := os.Create(cpuProfilePath(name))
+		if err != nil {
+			log.Fatalf("Failed to create CPU profile. %s\n", err)
+		}
+		defer func() { _ = f.Close() }()
+		if err = pprof.StartCPUProfile(f); err != nil {
+			log.Fatalf("Failed to start CPU profile. %s\n", err)
+		}
+		run()
+		pprof.StopCPUProfile()
+	} else {
+		run()
+	}
+	if 0 < len(memProfileDir) {
+		if err := os.MkdirAll(memProfileDir, 0755); err != nil {
+			log.Fatalf("Failed to create %s. %s\n", memProfileDir, err)
+		}
+		if err := writeMemProfile(memProfilePath(name), before, allocsProfile()); err != nil {
+			log.Fatalf("Failed to write memory profile. %s\n", err)
+		}
+	}
+}
+
+// allocsProfile returns the current allocs profile. A GC is run first so the
+// profile is up to date.
+func allocsProfile() []byte {
+	var buf bytes.Buffer
+	runtime.GC()
+	_ = pprof.Lookup("allocs").WriteTo(&buf, 0)
+	return buf.Bytes()
+}
+
+// writeMemProfile writes the allocations made between the before and after
+// profiles. The allocs profile covers everything allocated since the program
+// started so the before profile is subtracted with go tool pprof. If that
+// fails the cumulative after profile is written instead.
+func writeMemProfile(path string, before, after []byte) (err error) {
+	var dir string
+	if dir, err = ioutil.TempDir("", "profile"); err != nil {
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	basePath := filepath.Join(dir, "before.pprof")
+	afterPath := filepath.Join(dir, "after.pprof")
+	if err = ioutil.WriteFile(basePath, before, 0644); err != nil {
+		return
+	}
+	if err = ioutil.WriteFile(afterPath, after, 0644); err != nil {
+		return
+	}
+	out, err := exec.Command("go", "tool", "pprof", "-proto", "-base", basePath, afterPath).Output()
+	if err != nil {
+		fmt.Printf(" *** go tool pprof failed, %s is cumulative. %s\n", path, err)
+		out = after
+	}
+	return ioutil.WriteFile(path, out, 0644)
+}
+
+// printProfiles displays the top functions in the profiles for each package
+// in the suite.
+func (s *suite) printProfiles(pkgs []*pkg) {
+	for _, p := range pkgs {
+		name := profileName(s.id(), p.name)
+		if 0 < len(cpuProfileDir) {
+			printProfileTop(p.name, "CPU", cpuProfilePath(name))
+		}
+		if 0 < len(memProfileDir) {
+			printProfileTop(p.name, "allocated", memProfilePath(name), "-sample_index=alloc_space")
+		}
+	}
+}
+
+func printProfileTop(pkgName, kind, path string, args ...string) {
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	args = append([]string{"tool", "pprof", "-top", "-nodecount=" + strconv.Itoa(profileTop)}, args...)
+	out, err := exec.Command("go", append(args, path)...).Output()
+	fmt.Println()
+	if err != nil {
+		fmt.Printf(" %8s >>> %s profile summary failed. %s <<<\n", pkgName, kind, err)
+		return
+	}
+	fmt.Printf(" %8s top %d %s (%s)\n", pkgName, profileTop, kind, path)
+	var show bool
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		// The header lines before the column names are not interesting.
+		if strings.Contains(line, "flat%") {
+			show = true
+		}
+		if show {
+			fmt.Printf(" %8s %s\n", "", line)
+		}
+	}
+}
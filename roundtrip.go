@@ -0,0 +1,275 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// roundTripDocs returns the documents checked by the round trip report keyed
+// by name. They are the sample file, the other single document JSON files in
+// the same directory, and the number heavy sample.
+func roundTripDocs() map[string][]byte {
+	docs := map[string][]byte{}
+	paths, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), "*.json"))
+	paths = append(paths, filename)
+	for _, path := range paths {
+		if path == smallLogFile || path == largeLogFile {
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read %s. %s\n", path, err)
+		}
+		docs[filepath.Base(path)] = data
+	}
+	docs["numbers sample"] = numberSample()
+	return docs
+}
+
+// jsonLeaf is a scalar value in a document along with the JSON for it as it
+// appears in the document.
+type jsonLeaf struct {
+	path string
+	text string
+}
+
+// jsonShape is the scalar values and the key order of each object in a
+// document.
+type jsonShape struct {
+	leaves []jsonLeaf
+	keys   map[string][]string
+}
+
+// shapeOf reads a document token by token so that the original text of
+// numbers and the order of keys are kept.
+func shapeOf(data []byte) (*jsonShape, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	shape := &jsonShape{keys: map[string][]string{}}
+	var err error
+	if err = shapeValue(dec, shape, "$"); err == nil {
+		if _, err = dec.Token(); err != io.EOF {
+			err = fmt.Errorf("extra data after document")
+		} else {
+			err = nil
+		}
+	}
+	return shape, err
+}
+
+func shapeValue(dec *json.Decoder, shape *jsonShape, path string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tt := tok.(type) {
+	case json.Delim:
+		if tt == '[' {
+			for i := 0; dec.More(); i++ {
+				if err = shapeValue(dec, shape, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		} else {
+			shape.keys[path] = []string{}
+			for dec.More() {
+				if tok, err = dec.Token(); err != nil {
+					return err
+				}
+				key, _ := tok.(string)
+				shape.keys[path] = append(shape.keys[path], key)
+				if err = shapeValue(dec, shape, path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+		_, err = dec.Token() // closing delimiter
+		return err
+	case json.Number:
+		shape.leaves = append(shape.leaves, jsonLeaf{path: path, text: string(tt)})
+	case string:
+		shape.leaves = append(shape.leaves, jsonLeaf{path: path, text: strconv.Quote(tt)})
+	default:
+		shape.leaves = append(shape.leaves, jsonLeaf{path: path, text: fmt.Sprint(tt)})
+	}
+	return nil
+}
+
+// roundTripDiff is the difference between an original document and the
+// output of a round trip.
+type roundTripDiff struct {
+	reordered   int // objects with keys in a different order
+	reformatted int // numbers with the same value written differently
+	changed     int // values that are different
+	dropped     int // values missing from the output
+	added       int // values in the output that were not in the original
+	example     string
+}
+
+func (d *roundTripDiff) note(example string) {
+	if len(d.example) == 0 {
+		d.example = example
+	}
+}
+
+// compareShapes compares the original document shape with the round trip
+// output shape.
+func compareShapes(orig, out *jsonShape) *roundTripDiff {
+	var d roundTripDiff
+	var paths []string
+	for path := range orig.keys {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if keys, has := out.keys[path]; has && !sameOrder(orig.keys[path], keys) {
+			d.reordered++
+			d.note(fmt.Sprintf("%s keys reordered", path))
+		}
+	}
+	outLeaves := map[string]string{}
+	for _, leaf := range out.leaves {
+		outLeaves[leaf.path] = leaf.text
+	}
+	origLeaves := map[string]bool{}
+	for _, leaf := range orig.leaves {
+		origLeaves[leaf.path] = true
+		text, has := outLeaves[leaf.path]
+		switch {
+		case !has:
+			d.dropped++
+			d.note(fmt.Sprintf("%s: %s dropped", leaf.path, leaf.text))
+		case text == leaf.text:
+		case sameNumber(text, leaf.text):
+			d.reformatted++
+			d.note(fmt.Sprintf("%s: %s -> %s", leaf.path, leaf.text, text))
+		default:
+			d.changed++
+			d.note(fmt.Sprintf("%s: %s -> %s", leaf.path, leaf.text, text))
+		}
+	}
+	for _, leaf := range out.leaves {
+		if !origLeaves[leaf.path] {
+			d.added++
+			d.note(fmt.Sprintf("%s: %s added", leaf.path, leaf.text))
+		}
+	}
+	return &d
+}
+
+// sameOrder returns true if the keys are in the same order ignoring keys
+// that are only in one of the lists.
+func sameOrder(k0, k1 []string) bool {
+	in := map[string]bool{}
+	for _, k := range k1 {
+		in[k] = true
+	}
+	var common []string
+	for _, k := range k0 {
+		if in[k] {
+			common = append(common, k)
+		}
+	}
+	i := 0
+	for _, k := range k1 {
+		if i < len(common) && k == common[i] {
+			i++
+		}
+	}
+	return i == len(common)
+}
+
+// sameNumber returns true if both are numbers with exactly the same value.
+// Negative zero is not the same as zero.
+func sameNumber(s0, s1 string) bool {
+	r0, ok0 := new(big.Rat).SetString(s0)
+	r1, ok1 := new(big.Rat).SetString(s1)
+	if !ok0 || !ok1 || r0.Cmp(r1) != 0 {
+		return false
+	}
+	return r0.Sign() != 0 || strings.HasPrefix(s0, "-") == strings.HasPrefix(s1, "-")
+}
+
+// roundTrip parses the data with the package, marshals the result, and
+// parses the output again with the package to make sure the package accepts
+// its own output.
+func roundTrip(p *pkg, data []byte) (out []byte, err error) {
+	var v interface{}
+	if v, err = safeParse(p, data); err != nil {
+		return nil, fmt.Errorf("parse: %s", err)
+	}
+	if out, err = safeMarshal(p, v); err != nil {
+		return nil, fmt.Errorf("marshal: %s", err)
+	}
+	if _, err = safeParse(p, out); err != nil {
+		return out, fmt.Errorf("parse output: %s", err)
+	}
+	return out, nil
+}
+
+// roundTripReport runs each corpus document through a parse, marshal, and
+// parse cycle with each package that can marshal and displays how the output
+// differs from the original.
+func roundTripReport(pkgs []*pkg) {
+	docs := roundTripDocs()
+	var names []string
+	for name := range docs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var rows [][]string
+	for _, name := range names {
+		data := docs[name]
+		orig, err := shapeOf(data)
+		if err != nil {
+			log.Fatalf("Failed to read %s. %s\n", name, err)
+		}
+		var compact bytes.Buffer
+		_ = json.Compact(&compact, data)
+		first := name
+		for _, p := range pkgs {
+			if p.parse == nil || p.marshal == nil {
+				continue
+			}
+			row := []string{first, p.name}
+			first = ""
+			out, err := roundTrip(p, data)
+			var shape *jsonShape
+			if err == nil {
+				shape, err = shapeOf(out)
+			}
+			if err != nil {
+				rows = append(rows, append(row, "", "", "", "", "", "", clip(err.Error())))
+				continue
+			}
+			d := compareShapes(orig, shape)
+			size := "same"
+			if !bytes.Equal(out, compact.Bytes()) {
+				size = fmt.Sprintf("%+d", len(out)-compact.Len())
+			}
+			rows = append(rows, append(row,
+				size,
+				strconv.Itoa(d.reordered),
+				strconv.Itoa(d.reformatted),
+				strconv.Itoa(d.changed),
+				strconv.Itoa(d.dropped),
+				strconv.Itoa(d.added),
+				clip(d.example),
+			))
+		}
+	}
+	fmt.Println()
+	fmt.Println("Round trip parse, marshal, and parse compared to the original (bytes compared to the compacted original)")
+	printTable([]string{"document", "package", "bytes", "reordered", "reformatted", "changed", "dropped", "added", "example"}, rows)
+}
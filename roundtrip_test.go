@@ -0,0 +1,44 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import "testing"
+
+func TestCompareShapes(t *testing.T) {
+	orig, err := shapeOf([]byte(`{"a":1.0,"b":[1,2,3],"c":{"x":-0,"y":"z"},"d":123456789012345678901}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := shapeOf([]byte(`{"b":[1,2],"a":1,"c":{"x":0,"y":"z"},"d":123456789012345680000,"e":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := compareShapes(orig, out)
+	expect := roundTripDiff{reordered: 1, reformatted: 1, changed: 2, dropped: 1, added: 1, example: "$ keys reordered"}
+	if *d != expect {
+		t.Errorf("expected %+v, got %+v", expect, *d)
+	}
+}
+
+func TestSameOrder(t *testing.T) {
+	for _, tc := range []struct {
+		k0     []string
+		k1     []string
+		expect bool
+	}{
+		{k0: []string{"a", "b", "c"}, k1: []string{"a", "b", "c"}, expect: true},
+		{k0: []string{"a", "b", "c"}, k1: []string{"a", "c"}, expect: true},
+		{k0: []string{"a", "c"}, k1: []string{"x", "a", "y", "c"}, expect: true},
+		{k0: []string{"a", "b", "c"}, k1: []string{"b", "a", "c"}, expect: false},
+	} {
+		if got := sameOrder(tc.k0, tc.k1); got != tc.expect {
+			t.Errorf("%v and %v expected %t, got %t", tc.k0, tc.k1, tc.expect, got)
+		}
+	}
+}
+
+func TestShapeOfExtra(t *testing.T) {
+	if _, err := shapeOf([]byte(`{"a":1} 2`)); err == nil {
+		t.Error("expected an error for extra data")
+	}
+}
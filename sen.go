@@ -0,0 +1,39 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/sen"
+)
+
+// senSample returns the sample written as Simple Encoding Notation (SEN)
+// with keys not quoted, no commas, and a comment before every few lines. Of
+// the packages compared only oj reads SEN. The others do not have a lenient
+// mode that accepts unquoted keys or comments.
+func senSample() []byte {
+	wr := sen.Writer{Options: ojg.Options{Indent: 2, Sort: true}}
+	lines := strings.Split(wr.SEN(loadSample()), "\n")
+	var sb strings.Builder
+	var inString bool // SEN strings can include newlines
+	for i, line := range lines {
+		if i%8 == 1 && !inString {
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			sb.WriteString(strings.Repeat(" ", indent))
+			sb.WriteString("// a comment\n")
+		}
+		for j := 0; j < len(line); j++ {
+			switch line[j] {
+			case '\\':
+				j++
+			case '"':
+				inString = !inString
+			}
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return []byte(sb.String())
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+)
+
+func TestSENSample(t *testing.T) {
+	sample := senSample()
+	if _, err := oj.Parse(sample); err == nil {
+		t.Error("expected strict JSON parsing of the SEN sample to fail")
+	}
+	v, err := sen.Parse(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, loadSample()) {
+		t.Error("SEN sample does not parse to the same value as the JSON sample")
+	}
+}
@@ -12,12 +12,36 @@ import (
 )
 
 var simdjsonPkg = pkg{
-	name: "simdjson",
+	name:   "simdjson",
+	module: "github.com/minio/simdjson-go",
 	calls: map[string]*call{
-		"parse":      {name: "Parse", fun: simdjsonParse},
-		"validate":   {name: "Validate", fun: simdjsonValidate},
-		"small-file": {name: "ParseReader", fun: simdjsonFileManySmall},
-		"large-file": {name: "ParseReader", fun: simdjsonFileManyLarge},
+		"parse":          {name: "Parse", fun: simdjsonParse},
+		"parse-error":    {name: "Parse", fun: simdjsonParseError},
+		"numbers":        {name: "Parse", fun: simdjsonParseNumbers},
+		"validate":       {name: "Validate", fun: simdjsonValidate},
+		"decode":         {name: "Iter", fun: simdjsonDecode},
+		"callback":       {name: "Iter", fun: simdjsonCallback},
+		"extract-fields": {name: "FindKey", fun: simdjsonExtractFields},
+		"extract-log":    {name: "FindKey", fun: simdjsonExtractLog},
+		"small-file":     {name: "ParseReader", fun: simdjsonFileManySmall},
+		"large-file":     {name: "ParseReader", fun: simdjsonFileManyLarge},
+	},
+	parse: func(data []byte) (interface{}, error) {
+		if !simdjson.SupportedCPU() {
+			return nil, errors.New("Unsupported CPU by simdjson")
+		}
+		pj, err := simdjson.Parse(data, nil)
+		if err != nil {
+			return nil, err
+		}
+		return simdjsonValue(pj)
+	},
+	validate: func(data []byte) error {
+		if !simdjson.SupportedCPU() {
+			return errors.New("Unsupported CPU by simdjson")
+		}
+		_, err := simdjson.Parse(data, nil)
+		return err
 	},
 }
 
@@ -43,6 +67,47 @@ func simdjsonParse(b *testing.B) {
 	}
 }
 
+func simdjsonParseError(b *testing.B) {
+	if !simdjson.SupportedCPU() {
+		benchErr = errors.New("Unsupported CPU by simdjson")
+		b.Fail()
+		return
+	}
+	sample, _ := errorSample()
+	b.ResetTimer()
+
+	var pj simdjson.ParsedJson
+	for n := 0; n < b.N; n++ {
+		if _, err := simdjson.Parse(sample, &pj); err == nil {
+			benchErr = errors.New("error not detected")
+			b.Fail()
+		}
+	}
+}
+
+func simdjsonParseNumbers(b *testing.B) {
+	if !simdjson.SupportedCPU() {
+		benchErr = errors.New("Unsupported CPU by simdjson")
+		b.Fail()
+		return
+	}
+	sample := numberSample()
+	b.ResetTimer()
+
+	var pj simdjson.ParsedJson
+	for n := 0; n < b.N; n++ {
+		parsed, err := simdjson.Parse(sample, &pj)
+		if err != nil {
+			benchErr = err
+			b.Fail()
+			break
+		}
+		if benchErr = simdjsonExtract(parsed); benchErr != nil {
+			b.Fail()
+		}
+	}
+}
+
 func simdjsonValidate(b *testing.B) {
 	if !simdjson.SupportedCPU() {
 		benchErr = errors.New("Unsupported CPU by simdjson")
@@ -59,6 +124,154 @@ func simdjsonValidate(b *testing.B) {
 	}
 }
 
+func simdjsonDecode(b *testing.B) {
+	if !simdjson.SupportedCPU() {
+		benchErr = errors.New("Unsupported CPU by simdjson")
+		b.Fail()
+		return
+	}
+	sample, _ := ioutil.ReadFile(filename)
+	expect := tokenExpect(sample)
+	b.ResetTimer()
+
+	var pj simdjson.ParsedJson
+	for n := 0; n < b.N; n++ {
+		parsed, err := simdjson.Parse(sample, &pj)
+		if err != nil {
+			benchErr = err
+			b.Fail()
+			return
+		}
+		var cnt int
+		if cnt, benchErr = simdjsonTokens(parsed); benchErr == nil {
+			benchErr = tokenCheck(cnt, expect)
+		}
+		if benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+// simdjsonTokens steps through the tape with AdvanceInto reading each value
+// and returns the number of tokens encountered. Object keys are strings on
+// the tape.
+func simdjsonTokens(pj *simdjson.ParsedJson) (cnt int, err error) {
+	iter := pj.Iter()
+	for {
+		switch iter.AdvanceInto() {
+		case simdjson.TagEnd:
+			return
+		case simdjson.TagRoot:
+			continue
+		case simdjson.TagString:
+			_, err = iter.StringBytes()
+		case simdjson.TagInteger:
+			_, err = iter.Int()
+		case simdjson.TagUint:
+			_, err = iter.Uint()
+		case simdjson.TagFloat:
+			_, err = iter.Float()
+		case simdjson.TagBoolTrue, simdjson.TagBoolFalse:
+			_, err = iter.Bool()
+		}
+		if err != nil {
+			return
+		}
+		cnt++
+	}
+}
+
+func simdjsonCallback(b *testing.B) {
+	if !simdjson.SupportedCPU() {
+		benchErr = errors.New("Unsupported CPU by simdjson")
+		b.Fail()
+		return
+	}
+	sample, _ := ioutil.ReadFile(filename)
+	expect := collectExpect(sample)
+	b.ResetTimer()
+
+	var pj simdjson.ParsedJson
+	var c collector
+	for n := 0; n < b.N; n++ {
+		c.reset()
+		parsed, err := simdjson.Parse(sample, &pj)
+		if err != nil {
+			benchErr = err
+			b.Fail()
+			return
+		}
+		iter := parsed.Iter()
+		iter.Advance()
+		var typ simdjson.Type
+		var root *simdjson.Iter
+		if typ, root, benchErr = iter.Root(nil); benchErr == nil {
+			if benchErr = simdjsonCollect(root, typ, &c); benchErr == nil {
+				benchErr = c.check(expect)
+			}
+		}
+		if benchErr != nil {
+			b.Fail()
+			return
+		}
+	}
+}
+
+// simdjsonCollect walks the value the iterator is on and feeds the values to
+// the collector.
+func simdjsonCollect(it *simdjson.Iter, typ simdjson.Type, c *collector) (err error) {
+	switch typ {
+	case simdjson.TypeObject:
+		var obj *simdjson.Object
+		if obj, err = it.Object(nil); err != nil {
+			return
+		}
+		var tmp simdjson.Iter
+		c.start()
+		for {
+			var key string
+			var t simdjson.Type
+			if key, t, err = obj.NextElement(&tmp); err != nil || t == simdjson.TypeNone {
+				break
+			}
+			c.key(key)
+			if err = simdjsonCollect(&tmp, t, c); err != nil {
+				return
+			}
+		}
+		c.end()
+	case simdjson.TypeArray:
+		var ary *simdjson.Array
+		if ary, err = it.Array(nil); err != nil {
+			return
+		}
+		ai := ary.Iter()
+		c.start()
+		for {
+			t := ai.Advance()
+			if t == simdjson.TypeNone {
+				break
+			}
+			if err = simdjsonCollect(&ai, t, c); err != nil {
+				return
+			}
+		}
+		c.end()
+	case simdjson.TypeString:
+		var s string
+		if s, err = it.String(); err == nil {
+			c.str(s)
+		}
+	case simdjson.TypeInt, simdjson.TypeUint, simdjson.TypeFloat:
+		var f float64
+		if f, err = it.Float(); err == nil {
+			c.num(f)
+		}
+	}
+	return
+}
+
 func simdjsonFileManySmall(b *testing.B) {
 	f := openSmallLogFile()
 	defer func() { _ = f.Close() }()
@@ -94,47 +307,145 @@ func simdjsonFileManySmall(b *testing.B) {
 	}
 }
 
+// simdjsonFileManyLarge is run in a child process since on larger files
+// such as the 5GB file used for a large file (not that large really)
+// simdjson apparently attempts to pull the whole file into memory which can
+// cause an out of memory error or get the application killed.
 func simdjsonFileManyLarge(b *testing.B) {
-	// On larger files such as the 5GB file used for a large file (not that
-	// large really) simdjson apparently attempts to pull the whole file into
-	// memory and which causes an out of memory error or kills the
-	// application.
-	benchErr = errors.New("out of memory")
-	b.Fail()
-	/*
-		f := openLargeLogFile()
-		defer func() { _ = f.Close() }()
-
-		b.ResetTimer()
-
-		for n := 0; n < b.N; n++ {
-			// simdjson closes the chan when done parsing so a new one has to be
-			// created on each parse.
-			done := make(chan bool)
-			rc := make(chan simdjson.Stream, 1000)
-			go func() {
-				cnt := 0
-				for {
-					v := <-rc
-					cnt++
-					if v.Error != nil {
-						if v.Error != io.EOF {
-							benchErr = v.Error
-							b.Fail()
-						}
-						break
-					}
-					if benchErr = simdjsonExtract(v.Value); benchErr != nil {
+	f := openLargeLogFile()
+	defer func() { _ = f.Close() }()
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		// simdjson closes the chan when done parsing so a new one has to be
+		// created on each parse.
+		done := make(chan bool)
+		rc := make(chan simdjson.Stream, 1000)
+		go func() {
+			cnt := 0
+			for {
+				v := <-rc
+				cnt++
+				if v.Error != nil {
+					if v.Error != io.EOF {
+						benchErr = v.Error
 						b.Fail()
 					}
+					break
+				}
+				if benchErr = simdjsonExtract(v.Value); benchErr != nil {
+					b.Fail()
+				}
+			}
+			done <- true
+		}()
+		_, _ = f.Seek(0, 0)
+		simdjson.ParseNDStream(f, rc, nil)
+		<-done
+	}
+}
+
+func simdjsonExtractFields(b *testing.B) {
+	if !simdjson.SupportedCPU() {
+		benchErr = errors.New("Unsupported CPU by simdjson")
+		b.Fail()
+		return
+	}
+	benchExtractFields(b, simdjsonExtractor(patientPaths))
+}
+
+func simdjsonExtractLog(b *testing.B) {
+	if !simdjson.SupportedCPU() {
+		benchErr = errors.New("Unsupported CPU by simdjson")
+		b.Fail()
+		return
+	}
+	benchExtractLog(b, simdjsonExtractor(logPaths))
+}
+
+// simdjsonExtractor parses into a reused ParsedJson and then walks the tape
+// to each value with FindKey and array iterators.
+func simdjsonExtractor(paths [][]interface{}) extractor {
+	var pj simdjson.ParsedJson
+	return func(data []byte, got []string) error {
+		parsed, err := simdjson.Parse(data, &pj)
+		if err != nil {
+			return err
+		}
+		for i, path := range paths {
+			if got[i], err = simdjsonFind(parsed, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// simdjsonFind returns the string value at the path or an empty string if
+// not found.
+func simdjsonFind(pj *simdjson.ParsedJson, path []interface{}) (string, error) {
+	var obj simdjson.Object
+	var ary simdjson.Array
+	var elem simdjson.Element
+	iter := pj.Iter()
+	iter.Advance()
+	typ, it, err := iter.Root(nil)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range path {
+		switch tp := p.(type) {
+		case string:
+			if typ != simdjson.TypeObject {
+				return "", nil
+			}
+			if _, err = it.Object(&obj); err != nil {
+				return "", err
+			}
+			if obj.FindKey(tp, &elem) == nil {
+				return "", nil
+			}
+			typ = elem.Type
+			it = &elem.Iter
+		case int:
+			if typ != simdjson.TypeArray {
+				return "", nil
+			}
+			if _, err = it.Array(&ary); err != nil {
+				return "", err
+			}
+			ai := ary.Iter()
+			for j := 0; j <= tp; j++ {
+				if typ = ai.Advance(); typ == simdjson.TypeNone {
+					return "", nil
 				}
-				done <- true
-			}()
-			_, _ = f.Seek(0, 0)
-			simdjson.ParseNDStream(f, rc, nil)
-			<-done
+			}
+			it = &ai
 		}
-	*/
+	}
+	if typ != simdjson.TypeString {
+		return "", nil
+	}
+	return it.String()
+}
+
+// simdjsonValue returns the first document in the parsed JSON as simple
+// types.
+func simdjsonValue(pj *simdjson.ParsedJson) (interface{}, error) {
+	var tmp simdjson.Iter
+	iter := pj.Iter()
+	if iter.Advance() != simdjson.TypeRoot {
+		return nil, errors.New("no content")
+	}
+	typ, it, err := iter.Root(&tmp)
+	if err != nil {
+		return nil, err
+	}
+	if typ == simdjson.TypeNone {
+		return nil, errors.New("no content")
+	}
+	return it.Interface()
 }
 
 func simdjsonExtract(pj *simdjson.ParsedJson) (err error) {
@@ -0,0 +1,316 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+)
+
+// cpuFlags are the CPU features that matter to one or more of the packages.
+// The simdjson package requires AVX2 and CLMUL (pclmulqdq).
+var cpuFlags = []string{"avx2", "pclmulqdq", "bmi2", "avx512f", "sse4_2"}
+
+type specs struct {
+	os        string
+	model     string
+	processor string
+	cores     string
+	speed     string
+	memory    string
+	flags     string
+	cpuLimit  string
+	memLimit  string
+	goVersion string
+	maxProcs  int
+	modules   []string
+}
+
+func getSpecs() (s *specs) {
+	// Assume MacOS and try system_profiler. If that fails assume linux and check /proc.
+	out, err := exec.Command("system_profiler", "-json", "SPHardwareDataType").Output()
+	if err == nil {
+		if s, err = macSpecs(out); err == nil {
+			var b []byte
+			if out, err = exec.Command("sw_vers", "-productName").Output(); err == nil {
+				b = append(b, bytes.TrimSpace(out)...)
+				b = append(b, ' ')
+			}
+			if out, err = exec.Command("sw_vers", "-productVersion").Output(); err == nil {
+				b = append(b, bytes.TrimSpace(out)...)
+			}
+			s.os = string(b)
+		}
+	}
+	if s == nil {
+		s = linuxSpecs()
+	}
+	s.goSpecs()
+
+	return
+}
+
+// linuxSpecs reads the specs from /etc/os-release, /proc, and /sys. Nothing
+// is required to be installed so it works on minimal containers as well.
+func linuxSpecs() *specs {
+	var s specs
+	if out, err := ioutil.ReadFile("/etc/os-release"); err == nil {
+		s.os = osRelease(string(out))
+	}
+	if len(s.os) == 0 {
+		if out, err := exec.Command("lsb_release", "-d").Output(); err == nil {
+			s.os = lsbDescription(string(out))
+		}
+	}
+	var mhz float64
+	if out, err := ioutil.ReadFile("/proc/cpuinfo"); err == nil {
+		mhz = s.cpuInfo(string(out))
+	}
+	// The model name usually includes the speed. If not use the max
+	// frequency and then the current frequency.
+	if out, err := ioutil.ReadFile("/sys/devices/system/cpu/cpu0/cpufreq/cpuinfo_max_freq"); err == nil && len(s.speed) == 0 {
+		if khz, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64); err == nil {
+			mhz = khz / 1000.0
+		}
+	}
+	if len(s.speed) == 0 && 0 < mhz {
+		s.speed = fmt.Sprintf("%.2fGHz", mhz/1000.0)
+	}
+	if out, err := ioutil.ReadFile("/proc/meminfo"); err == nil {
+		s.memInfo(string(out))
+	}
+	// cgroup v2 first and then v1.
+	if out, err := ioutil.ReadFile("/sys/fs/cgroup/cpu.max"); err == nil {
+		s.cpuLimit = cgroupCPULimit(string(out))
+	} else if quota, err := ioutil.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_quota_us"); err == nil {
+		if period, err := ioutil.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_period_us"); err == nil {
+			s.cpuLimit = cgroupCPULimit(string(quota) + " " + string(period))
+		}
+	}
+	if out, err := ioutil.ReadFile("/sys/fs/cgroup/memory.max"); err == nil {
+		s.memLimit = cgroupMemLimit(string(out))
+	} else if out, err := ioutil.ReadFile("/sys/fs/cgroup/memory/memory.limit_in_bytes"); err == nil {
+		s.memLimit = cgroupMemLimit(string(out))
+	}
+	return &s
+}
+
+// goSpecs sets the go runtime values and module versions.
+func (s *specs) goSpecs() {
+	s.goVersion = fmt.Sprintf("%s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	s.maxProcs = runtime.GOMAXPROCS(0)
+	// Standard library packages are covered by the go version.
+	for _, p := range packages {
+		if strings.Contains(strings.Split(p.module, "/")[0], ".") {
+			s.modules = append(s.modules, p.module+" "+moduleVersion(p.module))
+		}
+	}
+}
+
+// moduleVersion returns the version of the module from the build info. If
+// the module has been replaced the replacement is included. Standard library
+// packages, which have no dot in the first path element, are versioned with
+// go.
+func moduleVersion(path string) string {
+	if !strings.Contains(strings.Split(path, "/")[0], ".") {
+		return runtime.Version()
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, m := range bi.Deps {
+			if m.Path != path {
+				continue
+			}
+			if r := m.Replace; r != nil {
+				if 0 < len(r.Version) {
+					return fmt.Sprintf("%s => %s %s", m.Version, r.Path, r.Version)
+				}
+				return fmt.Sprintf("%s => %s", m.Version, r.Path)
+			}
+			return m.Version
+		}
+	}
+	return "unknown"
+}
+
+func (s *specs) display() {
+	fmt.Println("Tests run on:")
+	if 0 < len(s.model) {
+		fmt.Printf(" Machine:         %s\n", s.model)
+	}
+	fmt.Printf(" OS:              %s\n", s.os)
+	fmt.Printf(" Processor:       %s\n", s.processor)
+	fmt.Printf(" Cores:           %s\n", s.cores)
+	fmt.Printf(" Processor Speed: %s\n", s.speed)
+	fmt.Printf(" Memory:          %s\n", s.memory)
+	if 0 < len(s.flags) {
+		fmt.Printf(" CPU Flags:       %s\n", s.flags)
+	}
+	if 0 < len(s.cpuLimit) {
+		fmt.Printf(" CPU Limit:       %s\n", s.cpuLimit)
+	}
+	if 0 < len(s.memLimit) {
+		fmt.Printf(" Memory Limit:    %s\n", s.memLimit)
+	}
+	fmt.Printf(" Go:              %s\n", s.goVersion)
+	fmt.Printf(" GOMAXPROCS:      %d\n", s.maxProcs)
+	for i, m := range s.modules {
+		if i == 0 {
+			fmt.Printf(" Modules:         %s\n", m)
+		} else {
+			fmt.Printf("                  %s\n", m)
+		}
+	}
+}
+
+// macSpecs extracts the specs from the JSON output of system_profiler.
+func macSpecs(out []byte) (*specs, error) {
+	js, err := oj.Parse(out)
+	if err != nil {
+		return nil, err
+	}
+	return &specs{
+		model:     alt.String(jp.C("SPHardwareDataType").N(0).C("machine_model").First(js)),
+		processor: alt.String(jp.C("SPHardwareDataType").N(0).C("cpu_type").First(js)),
+		cores:     alt.String(jp.C("SPHardwareDataType").N(0).C("number_processors").First(js)),
+		speed:     alt.String(jp.C("SPHardwareDataType").N(0).C("current_processor_speed").First(js)),
+		memory:    alt.String(jp.C("SPHardwareDataType").N(0).C("physical_memory").First(js)),
+	}, nil
+}
+
+// lsbDescription returns the description from the output of lsb_release -d.
+func lsbDescription(out string) string {
+	parts := strings.Split(out, ":")
+	if 1 < len(parts) {
+		return strings.TrimSpace(parts[1])
+	}
+	return ""
+}
+
+// osRelease returns the PRETTY_NAME from the contents of /etc/os-release or
+// the NAME and VERSION_ID if there is no PRETTY_NAME.
+func osRelease(out string) string {
+	values := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(parts) == 2 {
+			values[parts[0]] = strings.Trim(parts[1], `"'`)
+		}
+	}
+	if name := values["PRETTY_NAME"]; 0 < len(name) {
+		return name
+	}
+	return strings.TrimSpace(values["NAME"] + " " + values["VERSION_ID"])
+}
+
+// cpuInfo sets the processor, speed, cores, and flags from the contents of
+// /proc/cpuinfo. The speed is only set if it is part of the model name. The
+// current speed of the first processor in MHz is returned.
+func (s *specs) cpuInfo(out string) (mhz float64) {
+	cnt := 0
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		switch key {
+		case "processor":
+			cnt++
+		case "model name":
+			parts = strings.Split(value, "@")
+			s.processor = strings.TrimSpace(parts[0])
+			if 1 < len(parts) {
+				s.speed = strings.TrimSpace(parts[1])
+			}
+		case "cpu MHz":
+			if mhz == 0 {
+				mhz, _ = strconv.ParseFloat(value, 64)
+			}
+		case "flags":
+			if len(s.flags) == 0 {
+				s.flags = selectFlags(strings.Fields(value))
+			}
+		}
+	}
+	s.cores = fmt.Sprintf("%d", cnt)
+
+	return
+}
+
+// selectFlags returns the cpuFlags that are present in flags. Missing flags
+// are prefixed with no-.
+func selectFlags(flags []string) string {
+	present := map[string]bool{}
+	for _, f := range flags {
+		present[f] = true
+	}
+	var selected []string
+	for _, f := range cpuFlags {
+		if present[f] {
+			selected = append(selected, f)
+		} else {
+			selected = append(selected, "no-"+f)
+		}
+	}
+	return strings.Join(selected, " ")
+}
+
+// memInfo sets the memory from the contents of /proc/meminfo.
+func (s *specs) memInfo(out string) {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "MemTotal") {
+			parts := strings.Split(line, ":")
+			if 1 < len(parts) {
+				s.memory = strings.TrimSpace(parts[1])
+				if strings.HasSuffix(s.memory, "kB") {
+					if i, err := strconv.Atoi(strings.Split(s.memory, " ")[0]); err == nil {
+						s.memory = fmt.Sprintf("%d GB", i/1000000)
+					}
+				}
+			}
+		}
+	}
+}
+
+// cgroupCPULimit returns the number of CPUs allowed by a cgroup quota and
+// period such as the contents of the cgroup v2 cpu.max file. An empty string
+// is returned if there is no limit.
+func cgroupCPULimit(out string) string {
+	fields := strings.Fields(out)
+	if len(fields) < 2 {
+		return ""
+	}
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || quota <= 0 { // max or -1 for no limit
+		return ""
+	}
+	period, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || period <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%g CPUs", quota/period)
+}
+
+// cgroupMemLimit returns the memory limit from the contents of the cgroup
+// v2 memory.max or v1 memory.limit_in_bytes file. An empty string is
+// returned if there is no limit.
+func cgroupMemLimit(out string) string {
+	limit, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	// cgroup v1 uses a very large value, rounded to the page size, for no
+	// limit.
+	if err != nil || limit <= 0 || 1<<60 <= limit {
+		return ""
+	}
+	return memSize(limit)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCPUInfo(t *testing.T) {
+	var s specs
+	s.cpuInfo(`processor	: 0
+vendor_id	: GenuineIntel
+model name	: Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz
+cpu MHz		: 800.051
+
+processor	: 1
+vendor_id	: GenuineIntel
+model name	: Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz
+cpu MHz		: 800.102
+`)
+	if s.processor != "Intel(R) Core(TM) i7-8700 CPU" {
+		t.Errorf("wrong processor %q", s.processor)
+	}
+	if s.speed != "3.20GHz" {
+		t.Errorf("wrong speed %q", s.speed)
+	}
+	if s.cores != "2" {
+		t.Errorf("wrong cores %q", s.cores)
+	}
+}
+
+func TestMemInfo(t *testing.T) {
+	var s specs
+	s.memInfo(`MemTotal:       16303716 kB
+MemFree:         1234567 kB
+`)
+	if s.memory != "16 GB" {
+		t.Errorf("wrong memory %q", s.memory)
+	}
+	s.memInfo("MemTotal: lots\n")
+	if s.memory != "lots" {
+		t.Errorf("wrong memory %q", s.memory)
+	}
+}
+
+func TestLsbDescription(t *testing.T) {
+	if got := lsbDescription("Description:\tUbuntu 20.04.2 LTS\n"); got != "Ubuntu 20.04.2 LTS" {
+		t.Errorf("wrong description %q", got)
+	}
+	if got := lsbDescription("nothing"); got != "" {
+		t.Errorf("expected an empty description, got %q", got)
+	}
+}
+
+func TestMacSpecs(t *testing.T) {
+	s, err := macSpecs([]byte(`{
+  "SPHardwareDataType": [{
+    "machine_model": "MacBookPro15,1",
+    "cpu_type": "6-Core Intel Core i9",
+    "number_processors": 6,
+    "current_processor_speed": "2.9 GHz",
+    "physical_memory": "32 GB"
+  }]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := specs{
+		model:     "MacBookPro15,1",
+		processor: "6-Core Intel Core i9",
+		cores:     "6",
+		speed:     "2.9 GHz",
+		memory:    "32 GB",
+	}
+	if !reflect.DeepEqual(*s, expect) {
+		t.Errorf("expected %+v, got %+v", expect, *s)
+	}
+	if _, err = macSpecs([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestCPUInfoFlagsAndMHz(t *testing.T) {
+	var s specs
+	mhz := s.cpuInfo(`processor	: 0
+model name	: AMD EPYC 7B13
+cpu MHz		: 2450.000
+flags		: fpu sse4_2 avx2 bmi2 pclmulqdq
+
+processor	: 1
+model name	: AMD EPYC 7B13
+cpu MHz		: 2600.000
+flags		: fpu sse4_2 avx2 bmi2 pclmulqdq
+`)
+	if s.speed != "" {
+		t.Errorf("expected no speed from the model name, got %q", s.speed)
+	}
+	if mhz != 2450.0 {
+		t.Errorf("expected 2450 MHz from the first processor, got %g", mhz)
+	}
+	if s.flags != "avx2 pclmulqdq bmi2 no-avx512f sse4_2" {
+		t.Errorf("wrong flags %q", s.flags)
+	}
+}
+
+func TestOSRelease(t *testing.T) {
+	if got := osRelease(`NAME="Alpine Linux"
+ID=alpine
+VERSION_ID=3.13.5
+PRETTY_NAME="Alpine Linux v3.13"
+`); got != "Alpine Linux v3.13" {
+		t.Errorf("wrong os %q", got)
+	}
+	if got := osRelease("NAME='Debian GNU/Linux'\nVERSION_ID=\"10\"\n"); got != "Debian GNU/Linux 10" {
+		t.Errorf("wrong os %q", got)
+	}
+}
+
+func TestCgroupLimits(t *testing.T) {
+	for _, tc := range []struct {
+		value  string
+		expect string
+	}{
+		{value: "max 100000\n", expect: ""},
+		{value: "200000 100000\n", expect: "2 CPUs"},
+		{value: "50000 100000\n", expect: "0.5 CPUs"},
+		{value: "-1\n 100000\n", expect: ""},
+	} {
+		if got := cgroupCPULimit(tc.value); got != tc.expect {
+			t.Errorf("cgroupCPULimit(%q) expected %q, got %q", tc.value, tc.expect, got)
+		}
+	}
+	for _, tc := range []struct {
+		value  string
+		expect string
+	}{
+		{value: "max\n", expect: ""},
+		{value: "4294967296\n", expect: "4.00 GB"},
+		{value: "9223372036854771712\n", expect: ""},
+	} {
+		if got := cgroupMemLimit(tc.value); got != tc.expect {
+			t.Errorf("cgroupMemLimit(%q) expected %q, got %q", tc.value, tc.expect, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	shuffle   bool
+	seed      int64
+	warmUp    bool
+	gcBetween bool
+	cpuList   string
+
+	shuffler *rand.Rand
+)
+
+// order returns the packages in the order they should be run. If shuffle is
+// set the order is random but reproducible with the same seed.
+func order(pkgs []*pkg) []*pkg {
+	if !shuffle {
+		return pkgs
+	}
+	if shuffler == nil {
+		shuffler = rand.New(rand.NewSource(seed))
+	}
+	shuffled := append([]*pkg{}, pkgs...)
+	shuffler.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	return shuffled
+}
+
+// prepare is called before a call is benchmarked. It runs a discarded
+// warm-up iteration and a GC if those options are set.
+func prepare(c *call) {
+	if warmUp {
+		restore := useBenchtime("1x")
+		_ = testing.Benchmark(c.fun)
+		restore()
+		benchErr = nil
+	}
+	if gcBetween {
+		runtime.GC()
+	}
+}
+
+// pin pins the process to the CPUs in the list and sets GOMAXPROCS to match
+// so goroutines are not competing for fewer CPUs than there are Ps.
+func pin(list string) error {
+	cpus, err := parseCPUList(list)
+	if err != nil {
+		return err
+	}
+	if err = pinCPUs(cpus); err != nil {
+		return err
+	}
+	runtime.GOMAXPROCS(len(cpus))
+
+	return nil
+}
+
+// parseCPUList parses a CPU list such as 0,2-3 in the same format as taskset
+// and the cpuset cgroup files.
+func parseCPUList(list string) (cpus []int, err error) {
+	seen := map[int]bool{}
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		bounds := strings.SplitN(part, "-", 2)
+		var first, last int
+		if first, err = strconv.Atoi(bounds[0]); err != nil {
+			return nil, fmt.Errorf("invalid CPU list %q", list)
+		}
+		last = first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("invalid CPU list %q", list)
+			}
+		}
+		if first < 0 || last < first {
+			return nil, fmt.Errorf("invalid CPU range %q in %q", part, list)
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			if !seen[cpu] {
+				seen[cpu] = true
+				cpus = append(cpus, cpu)
+			}
+		}
+	}
+	return
+}
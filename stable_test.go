@@ -0,0 +1,61 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCPUList(t *testing.T) {
+	for _, tc := range []struct {
+		list   string
+		expect []int
+	}{
+		{list: "0", expect: []int{0}},
+		{list: "0,2-3", expect: []int{0, 2, 3}},
+		{list: " 4-5 , 1, 5", expect: []int{4, 5, 1}},
+	} {
+		cpus, err := parseCPUList(tc.list)
+		if err != nil {
+			t.Errorf("%q failed. %s", tc.list, err)
+			continue
+		}
+		if !reflect.DeepEqual(cpus, tc.expect) {
+			t.Errorf("%q expected %v, got %v", tc.list, tc.expect, cpus)
+		}
+	}
+	for _, list := range []string{"", "a", "1-", "3-1", "-1"} {
+		if _, err := parseCPUList(list); err == nil {
+			t.Errorf("expected an error for %q", list)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	defer func(s bool, sd int64) { shuffle, seed, shuffler = s, sd, nil }(shuffle, seed)
+
+	pkgs := []*pkg{{name: "a"}, {name: "b"}, {name: "c"}, {name: "d"}, {name: "e"}}
+	shuffle = false
+	if got := order(pkgs); !reflect.DeepEqual(got, pkgs) {
+		t.Error("expected the same order when not shuffled")
+	}
+	names := func() (list []string) {
+		for _, p := range order(pkgs) {
+			list = append(list, p.name)
+		}
+		return
+	}
+	shuffle = true
+	seed = 7
+	shuffler = nil
+	first := [][]string{names(), names()}
+	shuffler = nil
+	second := [][]string{names(), names()}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the same order with the same seed, got %v and %v", first, second)
+	}
+	if len(first[0]) != len(pkgs) || pkgs[0].name != "a" {
+		t.Error("expected all packages without changing the original order")
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// TagNames is a struct used to check untagged and case-insensitive field
+// mapping.
+type TagNames struct {
+	FirstName string
+	LastName  string
+}
+
+// TagRename is a struct used to check renamed fields.
+type TagRename struct {
+	First string `json:"first_name"`
+	Last  string `json:"surname"`
+}
+
+// TagOmitEmpty is a struct used to check the omitempty option.
+type TagOmitEmpty struct {
+	Name  string            `json:"name,omitempty"`
+	Count int               `json:"count,omitempty"`
+	Ratio float64           `json:"ratio,omitempty"`
+	Flag  bool              `json:"flag,omitempty"`
+	List  []int             `json:"list,omitempty"`
+	Map   map[string]string `json:"map,omitempty"`
+	Ptr   *int              `json:"ptr,omitempty"`
+	Keep  string            `json:"keep"`
+}
+
+// TagString is a struct used to check the string option.
+type TagString struct {
+	Int   int64   `json:"int,string"`
+	Float float64 `json:"float,string"`
+	Bool  bool    `json:"bool,string"`
+	Str   string  `json:"str,string"`
+}
+
+// TagBase is a struct embedded in other structs used to check embedded
+// struct support.
+type TagBase struct {
+	ID   string `json:"id"`
+	Kind string
+}
+
+// TagEmbedded is a struct used to check embedded struct support.
+type TagEmbedded struct {
+	TagBase
+	Name string `json:"name"`
+}
+
+// TagEmbeddedPtr is a struct used to check embedded struct pointer support.
+type TagEmbeddedPtr struct {
+	*TagBase
+	Name string `json:"name"`
+}
+
+// TagEmbeddedNamed is a struct used to check that a tagged embedded struct is
+// nested instead of flattened.
+type TagEmbeddedNamed struct {
+	TagBase `json:"base"`
+	Name    string `json:"name"`
+}
+
+// TagSkip is a struct used to check the json:"-" tag.
+type TagSkip struct {
+	Name   string `json:"name"`
+	Secret string `json:"-"`
+	Dash   string `json:"-,"`
+}
+
+// TagUnexported is a struct used to check that unexported fields are
+// ignored.
+type TagUnexported struct {
+	Name   string `json:"name"`
+	hidden string
+}
+
+type tagCase struct {
+	feature string
+	value   interface{}        // marshalled, skipped if nil
+	input   string             // unmarshalled, skipped if empty
+	target  func() interface{} // returns a pointer to unmarshal into
+}
+
+var tagCases = []*tagCase{
+	{
+		feature: "untagged names",
+		value:   &TagNames{FirstName: "Peter", LastName: "Chalmers"},
+		input:   `{"FirstName":"Peter","LastName":"Chalmers"}`,
+		target:  func() interface{} { return &TagNames{} },
+	},
+	{
+		feature: "case-insensitive",
+		input:   `{"firstname":"Peter","LASTNAME":"Chalmers"}`,
+		target:  func() interface{} { return &TagNames{} },
+	},
+	{
+		feature: "renamed",
+		value:   &TagRename{First: "Peter", Last: "Chalmers"},
+		input:   `{"first_name":"Peter","surname":"Chalmers"}`,
+		target:  func() interface{} { return &TagRename{} },
+	},
+	{
+		feature: "omitempty",
+		value:   &TagOmitEmpty{List: []int{}, Map: map[string]string{}},
+		input:   `{"keep":""}`,
+		target:  func() interface{} { return &TagOmitEmpty{} },
+	},
+	{
+		feature: "string option",
+		value:   &TagString{Int: 12345, Float: 1.5, Bool: true, Str: "x"},
+		input:   `{"int":"12345","float":"1.5","bool":"true","str":"\"x\""}`,
+		target:  func() interface{} { return &TagString{} },
+	},
+	{
+		feature: "embedded",
+		value:   &TagEmbedded{TagBase: TagBase{ID: "p1", Kind: "person"}, Name: "Peter"},
+		input:   `{"id":"p1","Kind":"person","name":"Peter"}`,
+		target:  func() interface{} { return &TagEmbedded{} },
+	},
+	{
+		feature: "embedded ptr",
+		value:   &TagEmbeddedPtr{TagBase: &TagBase{ID: "p1", Kind: "person"}, Name: "Peter"},
+		input:   `{"id":"p1","Kind":"person","name":"Peter"}`,
+		target:  func() interface{} { return &TagEmbeddedPtr{} },
+	},
+	{
+		feature: "embedded tagged",
+		value:   &TagEmbeddedNamed{TagBase: TagBase{ID: "p1", Kind: "person"}, Name: "Peter"},
+		input:   `{"base":{"id":"p1","Kind":"person"},"name":"Peter"}`,
+		target:  func() interface{} { return &TagEmbeddedNamed{} },
+	},
+	{
+		feature: `json:"-"`,
+		value:   &TagSkip{Name: "Peter", Secret: "shh", Dash: "dash"},
+		input:   `{"name":"Peter","Secret":"shh","-":"dash"}`,
+		target:  func() interface{} { return &TagSkip{} },
+	},
+	{
+		feature: "unexported",
+		value:   &TagUnexported{Name: "Peter", hidden: "shh"},
+		input:   `{"name":"Peter","hidden":"shh"}`,
+		target:  func() interface{} { return &TagUnexported{} },
+	},
+}
+
+// tagCoverage checks the marshal and unmarshal behavior of each package that
+// supports structs against the encoding/json behavior for each of the
+// tagCases and then displays the results as a compatibility table.
+func tagCoverage(pkgs []*pkg) {
+	header := []string{"feature"}
+	for _, p := range pkgs {
+		header = append(header, p.name)
+	}
+	var rows [][]string
+	var diffs []string
+	for _, tc := range tagCases {
+		if tc.value != nil {
+			expect, _ := json.Marshal(tc.value)
+			row := []string{tc.feature + " (marshal)"}
+			for _, p := range pkgs {
+				if p.marshal == nil {
+					row = append(row, "--")
+					continue
+				}
+				out, err := safeMarshal(p, tc.value)
+				switch {
+				case err != nil:
+					row = append(row, "error")
+					diffs = append(diffs, fmt.Sprintf("%s %s (marshal): %s", p.name, tc.feature, clip(err.Error())))
+				case bytes.Equal(expect, out):
+					row = append(row, "ok")
+				case sameJSON(expect, out):
+					row = append(row, "order")
+					diffs = append(diffs, fmt.Sprintf("%s %s (marshal): key order %s",
+						p.name, tc.feature, out))
+				default:
+					row = append(row, "differs")
+					diffs = append(diffs, fmt.Sprintf("%s %s (marshal): expected %s, got %s",
+						p.name, tc.feature, expect, out))
+				}
+			}
+			rows = append(rows, row)
+		}
+		if 0 < len(tc.input) {
+			expect := tc.target()
+			_ = json.Unmarshal([]byte(tc.input), expect)
+			row := []string{tc.feature + " (unmarshal)"}
+			for _, p := range pkgs {
+				if p.unmarshal == nil {
+					row = append(row, "--")
+					continue
+				}
+				out := tc.target()
+				err := safeUnmarshal(p, []byte(tc.input), out)
+				switch {
+				case err != nil:
+					row = append(row, "error")
+					diffs = append(diffs, fmt.Sprintf("%s %s (unmarshal): %s", p.name, tc.feature, clip(err.Error())))
+				case reflect.DeepEqual(expect, out):
+					row = append(row, "ok")
+				default:
+					row = append(row, "differs")
+					diffs = append(diffs, fmt.Sprintf("%s %s (unmarshal): expected %+v, got %+v",
+						p.name, tc.feature, reflect.ValueOf(expect).Elem(), reflect.ValueOf(out).Elem()))
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	fmt.Println()
+	fmt.Println("Struct tag and field mapping compatibility with encoding/json")
+	printTable(header, rows)
+	fmt.Println(" (order indicates the same members but not in the encoding/json order)")
+	if 0 < len(diffs) {
+		fmt.Println()
+		for _, d := range diffs {
+			fmt.Printf(" %s\n", d)
+		}
+	}
+}
+
+// sameJSON returns true if the two JSON documents are equal other than the
+// order of object members.
+func sameJSON(j0, j1 []byte) bool {
+	var v0 interface{}
+	var v1 interface{}
+	if json.Unmarshal(j0, &v0) != nil || json.Unmarshal(j1, &v1) != nil {
+		return false
+	}
+	return reflect.DeepEqual(v0, v1)
+}
+
+// clip shortens long error messages so the table notes stay readable.
+func clip(s string) string {
+	if 100 < len(s) {
+		s = s[:97] + "..."
+	}
+	return s
+}
+
+// safeMarshal calls the package marshal function and turns a panic into an
+// error so one misbehaving package does not stop the check.
+func safeMarshal(p *pkg, v interface{}) (out []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return p.marshal(v)
+}
+
+// safeUnmarshal calls the package unmarshal function and turns a panic into an
+// error so one misbehaving package does not stop the check.
+func safeUnmarshal(p *pkg, data []byte, v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return p.unmarshal(data, v)
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2021, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+)
+
+// tokenExpect returns the number of tokens in the JSON document as counted
+// by the encoding/json Decoder. Each object and array start and end, each
+// object key, and each scalar value is one token. The count is used to
+// verify the decode suite calls all see the same tokens.
+func tokenExpect(data []byte) (cnt int) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			log.Fatalf("Failed to tokenize %s. %s\n", filename, err)
+		}
+		cnt++
+	}
+	return
+}
+
+// tokenCheck returns an error if the token count is not the expected count.
+func tokenCheck(cnt, expect int) error {
+	if cnt != expect {
+		return fmt.Errorf("counted %d tokens, expected %d", cnt, expect)
+	}
+	return nil
+}